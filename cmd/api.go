@@ -2,6 +2,13 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -23,6 +30,22 @@ func apiCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
 				return err
 			}
 
+			if err := checkMigrations(cmd.Context(), cfg, log, dbpool); err != nil {
+				return err
+			}
+
+			if (cfg.Server.TLSCert == "") != (cfg.Server.TLSKey == "") {
+				return fmt.Errorf("both --tls-cert and --tls-key are required")
+			}
+
+			if cfg.Server.TLSClientCA != "" && cfg.Server.TLSCert == "" {
+				return fmt.Errorf("--tls-client-ca requires --tls-cert and --tls-key")
+			}
+
+			if (len(cfg.Server.TLSClientDomains) > 0 || cfg.Server.TLSClientAllDomains) && cfg.Server.TLSClientCA == "" {
+				return fmt.Errorf("--tls-client-domains and --tls-client-all-domains require --tls-client-ca")
+			}
+
 			srv := httpserver.New(cfg, log)
 			srv.RegisterHandlers(dbpool)
 			a := api{
@@ -48,6 +71,87 @@ func apiCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
 func apiFlags(fs *pflag.FlagSet, cfg *config.Config) {
 	fs.StringVarP(&cfg.Server.Address, "bind", "b", "0.0.0.0:10111", "bind address")
 	fs.StringVarP(&cfg.Server.Token, "access-token", "t", "", "access token")
+	fs.StringVarP(&cfg.AttachmentDir, "attachment", "a", "./attachments", "directory with stored attachment files")
+	fs.StringVar(&cfg.Server.TLSCert, "tls-cert", "", "TLS certificate file, reloaded on change; serve plain HTTP if empty")
+	fs.StringVar(&cfg.Server.TLSKey, "tls-key", "", "TLS private key file, reloaded on change")
+	fs.StringVar(&cfg.Server.TLSClientCA, "tls-client-ca", "", "CA file to verify client certificates, which are accepted instead of the access token")
+	fs.Var(newClientDomainsValue(&cfg.Server.TLSClientDomains), "tls-client-domains",
+		"domains of client certificates as <common name>=<domain id>[,<domain id>...], may be repeated")
+	fs.BoolVar(&cfg.Server.TLSClientAllDomains, "tls-client-all-domains", false,
+		"grant client certificates without --tls-client-domains access to all domains")
+	fs.Float64Var(&cfg.Server.RateLimit, "rate-limit", 10, "requests per second per token or IP address, 0 to disable")
+	fs.IntVar(&cfg.Server.RateBurst, "rate-burst", 20, "burst of requests per token or IP address")
+	fs.Float64Var(&cfg.Server.IPRateLimit, "ip-rate-limit", 50, "requests per second per IP address before authentication, 0 to disable")
+	fs.IntVar(&cfg.Server.IPRateBurst, "ip-rate-burst", 100, "burst of requests per IP address before authentication")
+	fs.IntVar(&cfg.Server.MaxDownloads, "max-downloads", 16, "concurrent attachment and bundle downloads, 0 to disable")
+	fs.Int64Var(&cfg.Server.DownloadBandwidth, "download-bandwidth", 0, "download bytes per second per token or IP address, 0 to disable")
+	fs.DurationVar(&cfg.Server.ShutdownTimeout, "shutdown-timeout", 30*time.Second, "time to wait for in-flight requests on shutdown")
+	fs.BoolVar(&cfg.AutoMigrate, "auto-migrate", false, "apply pending database migrations on startup")
+	migrateFlagSet(fs, cfg)
+	fs.StringVar(&cfg.Server.SigningKey, "signing-key", "", "secret key of signed attachment URLs, disabled if empty")
+	fs.DurationVar(&cfg.Server.SignedURLTTL, "signed-url-ttl", time.Hour, "lifetime of signed attachment URLs")
+}
+
+// clientDomainsValue implements pflag.Value interface for the map of client certificate common names to domain IDs.
+type clientDomainsValue struct {
+	m *map[string][]int64
+}
+
+func newClientDomainsValue(p *map[string][]int64) *clientDomainsValue {
+	return &clientDomainsValue{m: p}
+}
+
+func (v *clientDomainsValue) Set(s string) error {
+	i := strings.LastIndex(s, "=")
+	if i < 1 {
+		return fmt.Errorf("expected <common name>=<domain id>[,<domain id>...], got %q", s)
+	}
+
+	var domains []int64
+	for _, id := range strings.Split(s[i+1:], ",") {
+		domain, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if err != nil || domain < 1 {
+			return fmt.Errorf("invalid domain ID %q", id)
+		}
+
+		domains = append(domains, domain)
+	}
+
+	if *v.m == nil {
+		*v.m = make(map[string][]int64)
+	}
+
+	(*v.m)[s[:i]] = append((*v.m)[s[:i]], domains...)
+
+	return nil
+}
+
+func (v *clientDomainsValue) String() string {
+	if v.m == nil || len(*v.m) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(*v.m))
+	for name := range *v.m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		ids := make([]string, 0, len((*v.m)[name]))
+		for _, id := range (*v.m)[name] {
+			ids = append(ids, strconv.FormatInt(id, 10))
+		}
+
+		values = append(values, name+"="+strings.Join(ids, ","))
+	}
+
+	return strings.Join(values, " ")
+}
+
+func (v *clientDomainsValue) Type() string {
+	return "stringToInt64s"
 }
 
 type api struct {
@@ -61,11 +165,10 @@ type api struct {
 }
 
 func (a *api) run(ctx context.Context) error {
-	a.errorCh = make(chan error)
-	defer close(a.errorCh)
+	a.errorCh = make(chan error, 1)
 	go func() {
 		a.log.Info("listening http", wlog.String("server", a.cfg.Server.Address))
-		if err := a.srv.Serve(); err != nil {
+		if err := a.srv.Serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			a.errorCh <- err
 		}
 	}()
@@ -74,6 +177,11 @@ func (a *api) run(ctx context.Context) error {
 	// this signal may come from the node or from sig-abort (ctrl-c)
 	select {
 	case <-ctx.Done():
+		a.log.Info("shutting down http server", wlog.Any("timeout", a.cfg.Server.ShutdownTimeout))
+		if err := a.srv.Stop(); err != nil {
+			return fmt.Errorf("stop http server: %v", err)
+		}
+
 		return nil
 	case err := <-a.errorCh:
 		return err
@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+	"github.com/kirychukyurii/fd-import/pkg/filestorage"
+)
+
+func domainCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use:          "domain",
+		Short:        "Manage imported domains",
+		SilenceUsage: true,
+	}
+
+	c.AddCommand(domainListCommand(cfg, log), domainShowCommand(cfg, log), domainCreateCommand(cfg, log),
+		domainRenameCommand(cfg, log), domainSetLanguageCommand(cfg, log), domainDeleteCommand(cfg, log))
+
+	return c
+}
+
+func domainListCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "List domains with the summary of imported data",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			domains, err := dbpool.Domains(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("domains: %v", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tLANGUAGE\tTICKETS\tCONVERSATIONS\tATTACHMENTS\tATTACHMENT SIZE\tLAST IMPORT")
+			for _, domain := range domains {
+				stats, err := dbpool.DomainStats(cmd.Context(), domain.ID)
+				if err != nil {
+					return fmt.Errorf("domain stats (%d): %v", domain.ID, err)
+				}
+
+				fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d\t%s\t%s\n", domain.ID, domain.Name, domain.Language, stats.Tickets,
+					stats.Conversations, stats.Attachments, filestorage.HumanSize(stats.AttachmentBytes), formatTime(stats.LastImportedAt))
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+func domainShowCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:          "show <name|id>",
+		Short:        "Show a domain with the summary of imported data",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			domain, err := findDomain(cmd, dbpool, args[0])
+			if err != nil {
+				return err
+			}
+
+			stats, err := dbpool.DomainStats(cmd.Context(), domain.ID)
+			if err != nil {
+				return fmt.Errorf("domain stats: %v", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "ID:\t%d\n", domain.ID)
+			fmt.Fprintf(w, "Name:\t%s\n", domain.Name)
+			fmt.Fprintf(w, "Language:\t%s\n", domain.Language)
+			fmt.Fprintf(w, "Tickets:\t%d\n", stats.Tickets)
+			fmt.Fprintf(w, "Raw tickets:\t%d\n", stats.RawTickets)
+			fmt.Fprintf(w, "Conversations:\t%d\n", stats.Conversations)
+			fmt.Fprintf(w, "Attachments:\t%d\n", stats.Attachments)
+			fmt.Fprintf(w, "Attachment size:\t%s\n", filestorage.HumanSize(stats.AttachmentBytes))
+			fmt.Fprintf(w, "Contacts:\t%d\n", stats.Contacts)
+			fmt.Fprintf(w, "Companies:\t%d\n", stats.Companies)
+			fmt.Fprintf(w, "Agents:\t%d\n", stats.Agents)
+			fmt.Fprintf(w, "Groups:\t%d\n", stats.Groups)
+			fmt.Fprintf(w, "Articles:\t%d\n", stats.Articles)
+			fmt.Fprintf(w, "Last import:\t%s\n", formatTime(stats.LastImportedAt))
+
+			return w.Flush()
+		},
+	}
+}
+
+func domainCreateCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:          "create <name>",
+		Short:        "Create a domain",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateDomainName(args[0]); err != nil {
+				return err
+			}
+
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			if _, err := dbpool.Domain(cmd.Context(), &models.Domain{Name: args[0]}); err == nil {
+				return fmt.Errorf("domain %q already exists", args[0])
+			} else if !errors.Is(err, db.ErrDBNoExists) {
+				return fmt.Errorf("domain: %v", err)
+			}
+
+			id, err := dbpool.CreateDomain(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("create domain: %v", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "created domain %q with id %d\n", args[0], id)
+
+			return nil
+		},
+	}
+}
+
+func domainRenameCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use:          "rename <name|id> <new-name>",
+		Short:        "Rename a domain and its attachment directory",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[1]
+			if err := validateDomainName(name); err != nil {
+				return err
+			}
+
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			domain, err := findDomain(cmd, dbpool, args[0])
+			if err != nil {
+				return err
+			}
+
+			if _, err := dbpool.Domain(cmd.Context(), &models.Domain{Name: name}); err == nil {
+				return fmt.Errorf("domain %q already exists", name)
+			} else if !errors.Is(err, db.ErrDBNoExists) {
+				return fmt.Errorf("domain: %v", err)
+			}
+
+			// attachments are stored in the directory named after the domain,
+			// so it has to follow the new name to keep downloads working, the directory of a domain
+			// named before names were validated may be the attachment root, which is never moved
+			oldDir, err := filestorage.DomainDir(cfg.AttachmentDir, domain.Name)
+			if err != nil {
+				return fmt.Errorf("rename attachment directory: %v", err)
+			}
+
+			newDir, err := filestorage.DomainDir(cfg.AttachmentDir, name)
+			if err != nil {
+				return fmt.Errorf("rename attachment directory: %v", err)
+			}
+			if filestorage.IsExist(oldDir) {
+				if filestorage.IsExist(newDir) {
+					return fmt.Errorf("attachment directory %s already exists", newDir)
+				}
+
+				if err := filestorage.Rename(oldDir, newDir); err != nil {
+					return fmt.Errorf("rename attachment directory: %v", err)
+				}
+			}
+
+			if err := dbpool.RenameDomain(cmd.Context(), domain.ID, name); err != nil {
+				if filestorage.IsExist(newDir) {
+					if rerr := filestorage.Rename(newDir, oldDir); rerr != nil {
+						log.Error("restore attachment directory", wlog.Err(rerr), wlog.String("dir", oldDir))
+					}
+				}
+
+				return fmt.Errorf("rename domain: %v", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "renamed domain %q (id %d) to %q\n", domain.Name, domain.ID, name)
+
+			return nil
+		},
+	}
+
+	c.Flags().StringVarP(&cfg.AttachmentDir, "attachment", "a", "./attachments", "directory with stored attachment files")
+
+	return c
+}
+
+func domainSetLanguageCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:          "set-language <name|id> <language>",
+		Short:        "Set the full-text search language of a domain, e.g. english or simple",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			domain, err := findDomain(cmd, dbpool, args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := dbpool.SetDomainLanguage(cmd.Context(), domain.ID, args[1]); err != nil {
+				return fmt.Errorf("set domain language: %v", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "set language of domain %q (id %d) to %q\n", domain.Name, domain.ID, args[1])
+
+			return nil
+		},
+	}
+}
+
+func domainDeleteCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	var (
+		dryRun            bool
+		yes               bool
+		removeAttachments bool
+		batch             uint64
+	)
+
+	c := &cobra.Command{
+		Use:          "delete <name>",
+		Short:        "Delete a domain and all of its imported data",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if batch < 1 {
+				return fmt.Errorf("--batch-size must be at least 1")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			domain, err := findDomain(cmd, dbpool, args[0])
+			if err != nil {
+				return err
+			}
+
+			rows, err := dbpool.DomainRows(cmd.Context(), domain.ID)
+			if err != nil {
+				return fmt.Errorf("domain rows: %v", err)
+			}
+
+			var attachmentDir string
+			if removeAttachments {
+				// the domain name may be empty or contain path elements, so the attachment root
+				// and paths outside it are refused
+				if attachmentDir, err = filestorage.DomainDir(cfg.AttachmentDir, domain.Name); err != nil {
+					return fmt.Errorf("remove attachments: %v", err)
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "domain %q (id %d) contains:\n", domain.Name, domain.ID)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for _, r := range rows {
+				fmt.Fprintf(w, "  %s:\t%d\n", r.Table, r.Rows)
+			}
+
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if removeAttachments {
+				fmt.Fprintf(out, "attachment directory %s will be removed\n", attachmentDir)
+			}
+
+			if dryRun {
+				return nil
+			}
+
+			if !yes {
+				ok, err := confirm(cmd.InOrStdin(), out, domain.Name)
+				if err != nil {
+					return err
+				}
+
+				if !ok {
+					return errors.New("deletion is not confirmed")
+				}
+			}
+
+			if err := dbpool.DeleteDomain(cmd.Context(), domain.ID, batch); err != nil {
+				return fmt.Errorf("delete domain: %v", err)
+			}
+
+			if removeAttachments {
+				if err := filestorage.RemoveAll(attachmentDir); err != nil {
+					return fmt.Errorf("remove attachments: %v", err)
+				}
+			}
+
+			log.Info("deleted domain", wlog.String("domain", domain.Name), wlog.Int64("id", domain.ID))
+
+			return nil
+		},
+	}
+
+	fs := c.Flags()
+	fs.BoolVar(&dryRun, "dry-run", false, "print the number of rows to be deleted from every table and exit")
+	fs.BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	fs.BoolVar(&removeAttachments, "remove-attachments", false, "remove the domain attachment directory")
+	fs.StringVarP(&cfg.AttachmentDir, "attachment", "a", "./attachments", "directory with stored attachment files")
+	fs.Uint64Var(&batch, "batch-size", 10000, "number of rows deleted by a single statement")
+
+	return c
+}
+
+// validateDomainName checks that the domain name can name the attachment directory of the domain:
+// it is not empty, neither . nor .., and contains no path separators.
+func validateDomainName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("domain name %q is invalid: it must not be empty, . or .. and must not contain / or \\", name)
+	}
+
+	return nil
+}
+
+// findDomain looks up the domain by the given name. If there is no domain with such name
+// and the name is a number, it looks up the domain by ID.
+func findDomain(cmd *cobra.Command, dbpool *db.Connection, name string) (*models.Domain, error) {
+	domain, err := dbpool.Domain(cmd.Context(), &models.Domain{Name: name})
+	if errors.Is(err, db.ErrDBNoExists) {
+		if id, perr := strconv.ParseInt(name, 10, 64); perr == nil {
+			domain, err = dbpool.Domain(cmd.Context(), &models.Domain{ID: id})
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, db.ErrDBNoExists) {
+			return nil, fmt.Errorf("domain %q not found", name)
+		}
+
+		return nil, fmt.Errorf("domain: %v", err)
+	}
+
+	return domain, nil
+}
+
+// confirm asks to type the expected value and reports whether the typed value matches.
+func confirm(in io.Reader, out io.Writer, expected string) (bool, error) {
+	fmt.Fprintf(out, "type %q to confirm: ", expected)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("read confirmation: %v", err)
+	}
+
+	return strings.TrimSpace(line) == expected, nil
+}
+
+// formatTime returns the time in RFC3339 format or "-" if it is not set.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// timeLayouts is the list of layouts accepted by time flags.
+var timeLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+
+// timeValue implements pflag.Value interface for time.Time flags.
+type timeValue struct {
+	t *time.Time
+}
+
+func newTimeValue(p *time.Time) *timeValue {
+	return &timeValue{t: p}
+}
+
+func (v *timeValue) Set(s string) error {
+	for _, layout := range timeLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			*v.t = t
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported time format %q, use one of %v", s, timeLayouts)
+}
+
+func (v *timeValue) String() string {
+	if v.t == nil || v.t.IsZero() {
+		return ""
+	}
+
+	return v.t.Format(time.RFC3339)
+}
+
+func (v *timeValue) Type() string {
+	return "time"
+}
+
+// filterTicket reports whether the ticket matches all conditions of the given filter.
+// Lower bounds of the time windows are inclusive, upper bounds are exclusive.
+// A ticket without a timestamp never matches a window on that timestamp.
+func filterTicket(f *config.Filter, t *models.Ticket) bool {
+	if f.SkipSpam && t.Spam {
+		return false
+	}
+
+	if f.SkipDeleted && t.Deleted {
+		return false
+	}
+
+	if !inWindow(t.CreatedAt, f.CreatedFrom, f.CreatedTo) || !inWindow(t.UpdatedAt, f.UpdatedFrom, f.UpdatedTo) {
+		return false
+	}
+
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, t.Status) {
+		return false
+	}
+
+	if len(f.RequesterIDs) > 0 && !slices.Contains(f.RequesterIDs, t.RequesterID) {
+		return false
+	}
+
+	if len(f.RequesterNames) > 0 && !slices.Contains(f.RequesterNames, t.RequesterName) {
+		return false
+	}
+
+	return true
+}
+
+// filterSet reports whether any condition of the given filter is set.
+func filterSet(f *config.Filter) bool {
+	return f.SkipSpam || f.SkipDeleted || !f.CreatedFrom.IsZero() || !f.CreatedTo.IsZero() ||
+		!f.UpdatedFrom.IsZero() || !f.UpdatedTo.IsZero() || len(f.Statuses) > 0 || len(f.RequesterIDs) > 0 ||
+		len(f.RequesterNames) > 0
+}
+
+// inWindow reports whether t is within [from, to). Zero bounds are ignored.
+func inWindow(t *time.Time, from, to time.Time) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+
+	if t == nil {
+		return false
+	}
+
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+
+	if !to.IsZero() && !t.Before(to) {
+		return false
+	}
+
+	return true
+}
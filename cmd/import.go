@@ -1,14 +1,18 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	"github.com/spf13/cobra"
@@ -25,6 +29,10 @@ import (
 
 var (
 	requesterNameRegexp        = regexp.MustCompile(`^/([^/]+)`)
+	referenceRegexp            = regexp.MustCompile(`^/(contacts|companies|agents|groups|ticket_fields)/[^/]*\.json$`)
+	ticketRecordsRegexp        = regexp.MustCompile(`^/.*/(\d+)/(time_entries|satisfaction_ratings)\.json$`)
+	solutionRegexp             = regexp.MustCompile(`^/solutions/(categories|folders|articles)/[^/]*\.json$`)
+	solutionAttachmentRegexp   = regexp.MustCompile(`^/solutions/articles/(\d+)/attachments/(\d+)-[^/]*$`)
 	attachmentRegexp           = regexp.MustCompile(`^/.*/(\d+)/attachments/(\d+)-.*\.(.*)$`)
 	attachmentWithoutExtRegexp = regexp.MustCompile(`^/.*/(\d+)/attachments/(\d+)-.*$`)
 )
@@ -45,6 +53,10 @@ func importCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
 				return fmt.Errorf("parsing flags: %w", err)
 			}
 
+			if err := validateDomainName(cfg.Domain); err != nil {
+				return fmt.Errorf("--domain: %v", err)
+			}
+
 			log := wlog.NewLogger(&wlog.LoggerConfiguration{
 				EnableConsole: true,
 				ConsoleLevel:  wlog.LevelInfo,
@@ -58,6 +70,10 @@ func importCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
 				return err
 			}
 
+			if err := checkMigrations(cmd.Context(), cfg, log, dbpool); err != nil {
+				return err
+			}
+
 			cfg.AttachmentDir = filepath.Join(cfg.AttachmentDir, cfg.Domain)
 			a := &app{
 				log:    log,
@@ -68,8 +84,10 @@ func importCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
 				stats: &stats{
 					processed:   atomic.Uint64{},
 					exists:      atomic.Uint64{},
+					filtered:    atomic.Uint64{},
 					tickets:     atomic.Uint64{},
 					attachments: atomic.Uint64{},
+					references:  atomic.Uint64{},
 				},
 			}
 
@@ -79,8 +97,11 @@ func importCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
 			}
 
 			a.log.Info("processed items", wlog.Any("processed", a.stats.processed.Load()),
-				wlog.Any("exists", a.stats.exists.Load()), wlog.Any("tickets", a.stats.tickets.Load()),
-				wlog.Any("attachments", a.stats.attachments.Load()))
+				wlog.Any("exists", a.stats.exists.Load()), wlog.Any("filtered", a.stats.filtered.Load()),
+				wlog.Any("tickets", a.stats.tickets.Load()),
+				wlog.Any("attachments", a.stats.attachments.Load()), wlog.Any("references", a.stats.references.Load()),
+				wlog.Any("records", a.stats.records.Load()),
+				wlog.Any("solutions", a.stats.solutions.Load()))
 
 			return err
 		},
@@ -98,13 +119,24 @@ func importCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
 // for each flag.
 func importFlagSet(fs *pflag.FlagSet, cfg *config.Config) {
 	fs.StringVarP(&cfg.ExportedPath, "path", "p", "./export-data", "base path to exported files")
-	fs.StringVar(&cfg.Domain, "domain", "", "domain name")
+	fs.StringVar(&cfg.Domain, "domain", "", "domain name, required, names the attachment directory of the domain")
 	fs.StringVarP(&cfg.AttachmentDir, "attachment", "a", "./attachments", "directory to store attachment files")
 	fs.IntVarP(&cfg.Workers, "workers-count", "w", 100, "number of concurrent workers")
+	fs.BoolVar(&cfg.AutoMigrate, "auto-migrate", false, "apply pending database migrations on startup")
+	migrateFlagSet(fs, cfg)
 	fs.StringVar(&cfg.S3.AccessKeyID, "s3.access-key", "", "S3 access key ID")
 	fs.StringVar(&cfg.S3.SecretAccessKey, "s3.secret-key", "", "S3 secret access key")
 	fs.StringVar(&cfg.S3.Region, "s3.region", "", "S3 region")
 	fs.StringVar(&cfg.S3.Bucket, "s3.bucket", "", "S3 bucket")
+	fs.Var(newTimeValue(&cfg.Filter.CreatedFrom), "filter.created-from", "import tickets created at or after the given time")
+	fs.Var(newTimeValue(&cfg.Filter.CreatedTo), "filter.created-to", "import tickets created before the given time")
+	fs.Var(newTimeValue(&cfg.Filter.UpdatedFrom), "filter.updated-from", "import tickets updated at or after the given time")
+	fs.Var(newTimeValue(&cfg.Filter.UpdatedTo), "filter.updated-to", "import tickets updated before the given time")
+	fs.Int64SliceVar(&cfg.Filter.Statuses, "filter.status", nil, "import tickets only with the given statuses")
+	fs.Int64SliceVar(&cfg.Filter.RequesterIDs, "filter.requester-id", nil, "import tickets only of the given requester IDs")
+	fs.StringSliceVar(&cfg.Filter.RequesterNames, "filter.requester-name", nil, "import tickets only of the given requester names")
+	fs.BoolVar(&cfg.Filter.SkipSpam, "filter.skip-spam", false, "skip tickets marked as spam")
+	fs.BoolVar(&cfg.Filter.SkipDeleted, "filter.skip-deleted", false, "skip deleted tickets")
 }
 
 type app struct {
@@ -117,13 +149,20 @@ type app struct {
 	domain int64
 	keys   map[string][]string
 	stats  *stats
+
+	// matches caches whether tickets match the filter by ticket directories, see ticketMatches.
+	matches sync.Map
 }
 
 type stats struct {
 	processed   atomic.Uint64
 	exists      atomic.Uint64
+	filtered    atomic.Uint64
 	tickets     atomic.Uint64
 	attachments atomic.Uint64
+	references  atomic.Uint64
+	records     atomic.Uint64
+	solutions   atomic.Uint64
 }
 
 // run executes the main logic of the application. It performs the following steps:
@@ -190,7 +229,14 @@ func (a *app) run(ctx context.Context) error {
 }
 
 // process executes the processing logic for the given key. It performs the following steps:
+//   - If the key is a reference data file (contacts, companies, agents, groups or ticket fields), calls the `processReferences`
+//     method to upsert its records.
+//   - If the key is a file of records linked to a ticket (time entries or satisfaction ratings), calls the
+//     `processTicketRecords` method to upsert them, unless the ticket doesn't match the filter.
+//   - If the key is a solutions file (categories, folders or articles), calls the `processSolutions` method to upsert
+//     its records, or the `processArticleAttachment` method to download the article attachment.
 //   - Checks if the ticket already exists in the database for the given domain and key. If so, returns without further processing.
+//   - Skips attachments of tickets that don't match the filter.
 //   - Retrieves the metadata of the S3 object using the `HeadObject` method of the bucket.
 //   - Logs the metadata of the S3 object.
 //   - Checks the content type of the S3 object and performs the appropriate processing based on the content type.
@@ -199,6 +245,43 @@ func (a *app) run(ctx context.Context) error {
 //   - Returns any processing errors that occur.
 func (a *app) process(ctx context.Context, key string) error {
 	a.stats.processed.Add(1)
+	if match := referenceRegexp.FindStringSubmatch(strings.TrimPrefix(key, a.cfg.ExportedPath)); match != nil {
+		if err := a.processReferences(ctx, match[1], key); err != nil {
+			return fmt.Errorf("%s: %v", match[1], err)
+		}
+
+		return nil
+	}
+
+	if match := solutionAttachmentRegexp.FindStringSubmatch(strings.TrimPrefix(key, a.cfg.ExportedPath)); match != nil {
+		a.stats.attachments.Add(1)
+		if err := a.processArticleAttachment(ctx, match[1], match[2], key); err != nil {
+			return fmt.Errorf("article attachment: %v", err)
+		}
+
+		return nil
+	}
+
+	if match := solutionRegexp.FindStringSubmatch(strings.TrimPrefix(key, a.cfg.ExportedPath)); match != nil {
+		if err := a.processSolutions(ctx, match[1], key); err != nil {
+			return fmt.Errorf("solutions %s: %v", match[1], err)
+		}
+
+		return nil
+	}
+
+	if match := ticketRecordsRegexp.FindStringSubmatch(strings.TrimPrefix(key, a.cfg.ExportedPath)); match != nil {
+		if !a.ticketMatches(ctx, path.Dir(key)) {
+			return nil
+		}
+
+		if err := a.processTicketRecords(ctx, match[2], match[1], key); err != nil {
+			return fmt.Errorf("%s: %v", match[2], err)
+		}
+
+		return nil
+	}
+
 	ok, err := a.dbpool.Ticket(ctx, a.domain, key)
 	if err != nil {
 		if !errors.Is(err, db.ErrDBNoExists) {
@@ -214,12 +297,15 @@ func (a *app) process(ctx context.Context, key string) error {
 	}
 
 	if strings.Contains(key, "/attachments/") {
+		if !a.ticketMatches(ctx, path.Dir(path.Dir(key))) {
+			return nil
+		}
+
 		a.stats.attachments.Add(1)
 		if err := a.processAttachment(ctx, key); err != nil {
 			return fmt.Errorf("attachment: %v", err)
 		}
 	} else {
-		a.stats.tickets.Add(1)
 		if err := a.processJSON(ctx, key); err != nil {
 			return fmt.Errorf("json: %v", err)
 		}
@@ -254,6 +340,7 @@ func (a *app) process(ctx context.Context, key string) error {
 //   - Retrieves the object from the bucket using the ReadObject method.
 //   - Unmarshals the JSON object into a models.Ticket struct.
 //   - Sets additional fields of the Ticket struct.
+//   - Skips the ticket if it doesn't match the configured filter, caching the decision for its records.
 //   - Creates the ticket in the database using the CreateTicket method of the dbpool.
 //   - Returns any error that occurs during the processing.
 func (a *app) processJSON(ctx context.Context, key string) error {
@@ -272,7 +359,19 @@ func (a *app) processJSON(ctx context.Context, key string) error {
 	target.Raw = object
 	target.DomainID = a.domain
 	target.RequesterName = match[1]
+	matches := filterTicket(a.cfg.Filter, &target)
+	if filterSet(a.cfg.Filter) {
+		a.matches.Store(strings.TrimSuffix(key, ".json"), matches)
+	}
+
+	if !matches {
+		a.stats.filtered.Add(1)
+		a.log.Debug("filtered", wlog.Any("key", key), wlog.Int64("ticket", target.ID))
+
+		return nil
+	}
 
+	a.stats.tickets.Add(1)
 	if err := a.dbpool.CreateTicket(ctx, &target); err != nil {
 		return fmt.Errorf("create ticket: %v", err)
 	}
@@ -301,14 +400,318 @@ func (a *app) processJSON(ctx context.Context, key string) error {
 	return nil
 }
 
+// ticketMatches reports whether the ticket of the given ticket directory matches the configured filter, so
+// attachments, time entries and satisfaction ratings of filtered tickets are skipped. The ticket is read from
+// the JSON file next to its directory, `<ticket id>.json`, only if the filter is set, and the decision is cached
+// per ticket directory, the same way as processJSON caches it. Records of tickets, which JSON file is missing
+// or can't be decoded, are skipped.
+func (a *app) ticketMatches(ctx context.Context, ticketDir string) bool {
+	if !filterSet(a.cfg.Filter) {
+		return true
+	}
+
+	if ok, cached := a.matches.Load(ticketDir); cached {
+		return ok.(bool)
+	}
+
+	key := ticketDir + ".json"
+	object, err := a.bucket.ReadObject(ctx, key)
+	if err != nil {
+		a.log.Warn("skip ticket records, read ticket object", wlog.Err(err), wlog.String("ticket", key))
+		a.matches.Store(ticketDir, false)
+
+		return false
+	}
+
+	var ticket models.Ticket
+	if err := json.Unmarshal(object, &ticket); err != nil {
+		a.log.Warn("skip ticket records, unmarshal ticket", wlog.Err(err), wlog.String("ticket", key))
+		a.matches.Store(ticketDir, false)
+
+		return false
+	}
+
+	ticket.RequesterName = requesterNameRegexp.FindStringSubmatch(strings.TrimPrefix(key, a.cfg.ExportedPath))[1]
+	ok := filterTicket(a.cfg.Filter, &ticket)
+	a.matches.Store(ticketDir, ok)
+	if !ok {
+		a.log.Debug("filtered ticket records", wlog.Any("ticket", key))
+	}
+
+	return ok
+}
+
+// processReferences processes the reference data file with the given key. The kind is the name of the directory
+// at the export root: contacts, companies, agents, groups or ticket_fields, nested directories are not read.
+// The file contains either a single record or an array of records, as returned by the Freshdesk API.
+// Records are upserted, so re-importing an updated export refreshes them.
+func (a *app) processReferences(ctx context.Context, kind, key string) error {
+	object, err := a.bucket.ReadObject(ctx, key)
+	if err != nil {
+		return fmt.Errorf("read object: %v", err)
+	}
+
+	var n int
+	switch kind {
+	case "contacts":
+		contacts, err := decodeRecords[models.Contact](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, c := range contacts {
+			c.AWSKey, c.DomainID = key, a.domain
+		}
+
+		if err := a.dbpool.CreateContacts(ctx, contacts); err != nil {
+			return fmt.Errorf("create contacts: %v", err)
+		}
+
+		n = len(contacts)
+	case "companies":
+		companies, err := decodeRecords[models.Company](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, c := range companies {
+			c.AWSKey, c.DomainID = key, a.domain
+		}
+
+		if err := a.dbpool.CreateCompanies(ctx, companies); err != nil {
+			return fmt.Errorf("create companies: %v", err)
+		}
+
+		n = len(companies)
+	case "agents":
+		agents, err := decodeRecords[models.Agent](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, ag := range agents {
+			ag.AWSKey, ag.DomainID = key, a.domain
+		}
+
+		if err := a.dbpool.CreateAgents(ctx, agents); err != nil {
+			return fmt.Errorf("create agents: %v", err)
+		}
+
+		n = len(agents)
+	case "groups":
+		groups, err := decodeRecords[models.Group](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, g := range groups {
+			g.AWSKey, g.DomainID = key, a.domain
+		}
+
+		if err := a.dbpool.CreateGroups(ctx, groups); err != nil {
+			return fmt.Errorf("create groups: %v", err)
+		}
+
+		n = len(groups)
+	case "ticket_fields":
+		fields, err := decodeRecords[models.TicketField](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, f := range fields {
+			f.AWSKey, f.DomainID = key, a.domain
+		}
+
+		if err := a.dbpool.CreateTicketFields(ctx, fields); err != nil {
+			return fmt.Errorf("create ticket fields: %v", err)
+		}
+
+		n = len(fields)
+	default:
+		return fmt.Errorf("unknown reference data %q", kind)
+	}
+
+	a.stats.references.Add(uint64(n))
+
+	return nil
+}
+
+// processTicketRecords processes the file of records linked to the ticket with the given ID. The kind is the name
+// of the file: time_entries or satisfaction_ratings. Records without the ticket ID are linked to the ticket
+// of the export directory. Records are upserted, so re-importing an updated export refreshes them.
+func (a *app) processTicketRecords(ctx context.Context, kind, ticketID, key string) error {
+	ticket, err := strconv.ParseInt(ticketID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse ticket ID: %v", err)
+	}
+
+	object, err := a.bucket.ReadObject(ctx, key)
+	if err != nil {
+		return fmt.Errorf("read object: %v", err)
+	}
+
+	var n int
+	switch kind {
+	case "time_entries":
+		entries, err := decodeRecords[models.TimeEntry](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, e := range entries {
+			e.AWSKey, e.DomainID = key, a.domain
+			if e.TicketID == 0 {
+				e.TicketID = ticket
+			}
+
+			e.TimeSpentMinutes = timeSpentMinutes(e.TimeSpent)
+		}
+
+		if err := a.dbpool.CreateTimeEntries(ctx, entries); err != nil {
+			return fmt.Errorf("create time entries: %v", err)
+		}
+
+		n = len(entries)
+	case "satisfaction_ratings":
+		ratings, err := decodeRecords[models.SatisfactionRating](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, r := range ratings {
+			r.AWSKey, r.DomainID = key, a.domain
+			if r.TicketID == 0 {
+				r.TicketID = ticket
+			}
+		}
+
+		if err := a.dbpool.CreateSatisfactionRatings(ctx, ratings); err != nil {
+			return fmt.Errorf("create satisfaction ratings: %v", err)
+		}
+
+		n = len(ratings)
+	default:
+		return fmt.Errorf("unknown ticket records %q", kind)
+	}
+
+	a.stats.records.Add(uint64(n))
+
+	return nil
+}
+
+// processSolutions processes the knowledge base file with the given key. The kind is the name of the export
+// directory under solutions/: categories, folders or articles. The file contains either a single record or an array
+// of records, as returned by the Freshdesk API. Records are upserted, so re-importing an updated export refreshes them.
+// Attachment metadata of articles is stored with the articles, files are downloaded by processArticleAttachment.
+func (a *app) processSolutions(ctx context.Context, kind, key string) error {
+	object, err := a.bucket.ReadObject(ctx, key)
+	if err != nil {
+		return fmt.Errorf("read object: %v", err)
+	}
+
+	var n int
+	switch kind {
+	case "categories":
+		categories, err := decodeRecords[models.SolutionCategory](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, c := range categories {
+			c.AWSKey, c.DomainID = key, a.domain
+		}
+
+		if err := a.dbpool.CreateSolutionCategories(ctx, categories); err != nil {
+			return fmt.Errorf("create categories: %v", err)
+		}
+
+		n = len(categories)
+	case "folders":
+		folders, err := decodeRecords[models.SolutionFolder](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, f := range folders {
+			f.AWSKey, f.DomainID = key, a.domain
+		}
+
+		if err := a.dbpool.CreateSolutionFolders(ctx, folders); err != nil {
+			return fmt.Errorf("create folders: %v", err)
+		}
+
+		n = len(folders)
+	case "articles":
+		articles, err := decodeRecords[models.SolutionArticle](object)
+		if err != nil {
+			return fmt.Errorf("unmarshal: %v", err)
+		}
+
+		for _, ar := range articles {
+			ar.AWSKey, ar.DomainID = key, a.domain
+		}
+
+		if err := a.dbpool.CreateSolutionArticles(ctx, articles); err != nil {
+			return fmt.Errorf("create articles: %v", err)
+		}
+
+		n = len(articles)
+	default:
+		return fmt.Errorf("unknown solutions %q", kind)
+	}
+
+	a.stats.solutions.Add(uint64(n))
+
+	return nil
+}
+
+// timeSpentMinutes returns the "hh:mm" duration in minutes, or 0 if it can't be parsed.
+func timeSpentMinutes(s string) int64 {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0
+	}
+
+	h, err := strconv.ParseInt(hours, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	m, err := strconv.ParseInt(minutes, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return h*60 + m
+}
+
+// decodeRecords decodes a single JSON object or an array of objects.
+func decodeRecords[T any](object []byte) ([]*T, error) {
+	trimmed := bytes.TrimSpace(object)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []*T
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, err
+		}
+
+		return records, nil
+	}
+
+	var record T
+	if err := json.Unmarshal(trimmed, &record); err != nil {
+		return nil, err
+	}
+
+	return []*T{&record}, nil
+}
+
 func (a *app) processAttachment(ctx context.Context, key string) error {
 	var (
 		ticketID     string
 		attachmentID string
 		extension    string
-		file         string
 		fileName     string
-		err          error
 	)
 
 	f := attachmentRegexp.FindStringSubmatch(strings.TrimPrefix(key, a.cfg.ExportedPath))
@@ -324,8 +727,22 @@ func (a *app) processAttachment(ctx context.Context, key string) error {
 		fileName = fmt.Sprintf("%s.%s", attachmentID, extension)
 	}
 
-	attachmentPath := filepath.Join(a.cfg.AttachmentDir, ticketID)
-	file = filepath.Join(attachmentPath, fileName)
+	return a.downloadAttachment(ctx, key, filepath.Join(a.cfg.AttachmentDir, ticketID), attachmentID, fileName)
+}
+
+// processArticleAttachment downloads the solution article attachment with the given key into the solutions
+// directory of the domain attachments, the same way as ticket attachments.
+func (a *app) processArticleAttachment(ctx context.Context, articleID, attachmentID, key string) error {
+	fileName := attachmentID + filepath.Ext(key)
+
+	return a.downloadAttachment(ctx, key, filepath.Join(a.cfg.AttachmentDir, filestorage.SolutionsDir, articleID),
+		attachmentID, fileName)
+}
+
+// downloadAttachment downloads the attachment object with the given key into the file of the attachment directory
+// and stores the file checksum. Existing files are skipped, partially downloaded ones are removed.
+func (a *app) downloadAttachment(ctx context.Context, key, attachmentPath, attachmentID, fileName string) (err error) {
+	file := filepath.Join(attachmentPath, fileName)
 	if filestorage.IsExist(file) {
 		a.log.Debug("exists", wlog.String("key", key), wlog.String("file", file))
 
@@ -352,6 +769,23 @@ func (a *app) processAttachment(ctx context.Context, key string) error {
 		return fmt.Errorf("download: %v", err)
 	}
 
+	// the attachment row may be not imported yet, the API computes the checksum on demand then
+	id, perr := strconv.ParseInt(attachmentID, 10, 64)
+	if perr != nil {
+		return nil
+	}
+
+	checksum, cerr := filestorage.Checksum(file)
+	if cerr != nil {
+		a.log.Warn("attachment checksum", wlog.Err(cerr), wlog.String("file", file))
+
+		return nil
+	}
+
+	if cerr := a.dbpool.SetAttachmentChecksum(ctx, a.domain, id, checksum); cerr != nil {
+		a.log.Warn("set attachment checksum", wlog.Err(cerr), wlog.String("file", file))
+	}
+
 	return nil
 }
 
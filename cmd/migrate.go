@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/pressly/goose/v3"
 	"github.com/spf13/cobra"
@@ -10,48 +14,117 @@ import (
 	"github.com/webitel/wlog"
 
 	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/migrations"
 	"github.com/kirychukyurii/fd-import/pkg/db"
 )
 
-func migrateCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
-	var directory string
+// migrateFunc is a goose command executed by the migrate subcommands.
+type migrateFunc func(ctx context.Context, db *sql.DB, dir string, opts ...goose.OptionsFunc) error
 
+func migrateCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
 	c := &cobra.Command{
 		Use:          "migrate",
 		Short:        "Apply database schema migrations",
 		SilenceUsage: true,
 		Version:      fmt.Sprintf("%s, commit %s, date %s", version, commit, commitDate),
-		Args: func(cmd *cobra.Command, args []string) error {
-
-			return nil
-		},
+		Args:         cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// apply CLI args to config
-			if err := cmd.ParseFlags(os.Args[1:]); err != nil {
-				return fmt.Errorf("parsing flags: %w", err)
-			}
+			return migrate(cmd.Context(), cfg, log, goose.UpContext)
+		},
+	}
 
-			pool, err := db.New(cmd.Context(), log, cfg.DSN)
-			if err != nil {
-				return err
-			}
+	migrateFlagSet(c.PersistentFlags(), cfg)
+	c.AddCommand(
+		migrateSubcommand(cfg, log, "up", "Migrate the database to the most recent version", goose.UpContext),
+		migrateSubcommand(cfg, log, "down", "Roll back the version by 1", goose.DownContext),
+		migrateSubcommand(cfg, log, "redo", "Re-run the latest migration", goose.RedoContext),
+		migrateSubcommand(cfg, log, "status", "Dump the migration status", goose.StatusContext),
+		migrateSubcommand(cfg, log, "version", "Print the current version of the database", goose.VersionContext),
+	)
 
-			goose.SetTableName(fmt.Sprintf("fresh.%s", goose.DefaultTablename))
-			if err := goose.UpContext(cmd.Context(), pool.STDLib(), directory); err != nil {
-				return err
-			}
+	return c
+}
 
-			return nil
+func migrateSubcommand(cfg *config.Config, log *wlog.Logger, use, short string, fn migrateFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:          use,
+		Short:        short,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return migrate(cmd.Context(), cfg, log, fn)
 		},
 	}
+}
 
-	directory = migrateFlagSet(c.PersistentFlags())
+func migrateFlagSet(fs *pflag.FlagSet, cfg *config.Config) {
+	fs.StringVarP(&cfg.MigrationsDir, "migrations", "m", "", "migrations directory, embedded migrations are used if empty")
+}
 
-	return c
+// migrate connects to the database and executes the goose command against the fresh.goose_db_version table.
+func migrate(ctx context.Context, cfg *config.Config, log *wlog.Logger, fn migrateFunc) error {
+	pool, err := db.New(ctx, log, cfg.DSN)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(ctx, pool.STDLib(), setupGoose(cfg.MigrationsDir)); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func migrateFlagSet(fs *pflag.FlagSet) string {
-	dir := fs.StringP("migrations", "m", "./migrations", "migrations directory")
+// setupGoose configures goose to use the fresh schema version table and the migrations source.
+// It uses embedded migrations, unless the directory is set, and returns the directory
+// to be passed to goose commands.
+func setupGoose(dir string) string {
+	goose.SetTableName(fmt.Sprintf("fresh.%s", goose.DefaultTablename))
+	if dir != "" {
+		goose.SetBaseFS(nil)
+
+		return dir
+	}
+
+	goose.SetBaseFS(migrations.FS)
+
+	return "."
+}
+
+// checkMigrations verifies the database schema is at the latest version before the command starts.
+// When migrations are pending, it applies them if auto-migrate is enabled, otherwise returns an error
+// which lists the pending migrations.
+func checkMigrations(ctx context.Context, cfg *config.Config, log *wlog.Logger, pool *db.Connection) error {
+	sqldb := pool.STDLib()
+	dir := setupGoose(cfg.MigrationsDir)
+	current, err := goose.GetDBVersionContext(ctx, sqldb)
+	if err != nil {
+		return fmt.Errorf("get database version: %v", err)
+	}
+
+	pending, err := goose.CollectMigrations(dir, current, goose.MaxVersion)
+	if err != nil {
+		if errors.Is(err, goose.ErrNoMigrationFiles) {
+			return nil
+		}
+
+		return fmt.Errorf("collect migrations: %v", err)
+	}
+
+	names := make([]string, 0, len(pending))
+	for _, m := range pending {
+		names = append(names, filepath.Base(m.Source))
+	}
+
+	if !cfg.AutoMigrate {
+		return fmt.Errorf("database schema version %d is behind, pending migrations: %s; run `migrate` command or use --auto-migrate",
+			current, strings.Join(names, ", "))
+	}
+
+	log.Info("applying pending migrations", wlog.Int64("version", current), wlog.Any("migrations", names))
+	if err := goose.UpContext(ctx, sqldb, dir); err != nil {
+		return fmt.Errorf("migrate: %v", err)
+	}
 
-	return *dir
+	return nil
 }
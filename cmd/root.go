@@ -64,7 +64,8 @@ func command() *cobra.Command {
 	}
 
 	flagSet(c.PersistentFlags(), cfg)
-	c.AddCommand(importCommand(cfg, log), migrateCommand(cfg, log), apiCommand(cfg, log))
+	c.AddCommand(importCommand(cfg, log), migrateCommand(cfg, log), apiCommand(cfg, log), domainCommand(cfg, log),
+		ticketCommand(cfg, log), tokenCommand(cfg, log))
 
 	return c
 }
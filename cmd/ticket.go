@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/auth"
+	"github.com/kirychukyurii/fd-import/pkg/bundle"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+	"github.com/kirychukyurii/fd-import/pkg/httpserver"
+	"github.com/kirychukyurii/fd-import/pkg/transcript"
+)
+
+func ticketCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use:          "ticket",
+		Short:        "Export imported tickets",
+		SilenceUsage: true,
+	}
+
+	c.AddCommand(ticketBundleCommand(cfg, log), ticketTranscriptCommand(cfg, log), ticketAttachmentURLCommand(cfg, log))
+
+	return c
+}
+
+func ticketBundleCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	var output string
+
+	c := &cobra.Command{
+		Use:          "bundle <domain> <ticket id>",
+		Short:        "Write a zip archive with the raw ticket JSON, transcript and attachments",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ticketID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("ticket id is invalid: %v", err)
+			}
+
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			domain, err := findDomain(cmd, dbpool, args[0])
+			if err != nil {
+				return err
+			}
+
+			b, err := bundle.Load(cmd.Context(), log, dbpool, cfg.AttachmentDir, domain, ticketID)
+			if err != nil {
+				return fmt.Errorf("load bundle: %v", err)
+			}
+
+			if output == "" {
+				output = b.Name()
+			}
+
+			return writeOutput(cmd, output, b.Write)
+		},
+	}
+
+	c.Flags().StringVarP(&cfg.AttachmentDir, "attachment", "a", "./attachments", "directory with stored attachment files")
+	c.Flags().StringVarP(&output, "output", "o", "", "output file, - for stdout (default \"ticket-<id>.zip\")")
+
+	return c
+}
+
+func ticketTranscriptCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	var (
+		output  string
+		baseURL string
+	)
+
+	c := &cobra.Command{
+		Use:          "transcript <domain> <ticket id>",
+		Short:        "Write a printable HTML transcript of a ticket",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ticketID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("ticket id is invalid: %v", err)
+			}
+
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			domain, err := findDomain(cmd, dbpool, args[0])
+			if err != nil {
+				return err
+			}
+
+			ticket, err := dbpool.TicketDetails(cmd.Context(), domain.ID, ticketID)
+			if err != nil {
+				return fmt.Errorf("ticket details: %v", err)
+			}
+
+			opts := &transcript.Options{}
+			if baseURL != "" {
+				opts.AttachmentURL = func(a *models.Attachment) string {
+					return fmt.Sprintf("%s/%d/ticket/%d/attachments/%d", strings.TrimSuffix(baseURL, "/"), domain.ID,
+						ticketID, a.ID)
+				}
+			}
+
+			if output == "" {
+				output = fmt.Sprintf("ticket-%d.html", ticketID)
+			}
+
+			return writeOutput(cmd, output, func(w io.Writer) error {
+				return transcript.Render(w, ticket, opts)
+			})
+		},
+	}
+
+	c.Flags().StringVarP(&output, "output", "o", "", "output file, - for stdout (default \"ticket-<id>.html\")")
+	c.Flags().StringVar(&baseURL, "base-url", "", "API base URL to link attachments to, attachments are not linked if empty")
+
+	return c
+}
+
+func ticketAttachmentURLCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	var baseURL string
+
+	c := &cobra.Command{
+		Use:          "attachment-url <domain> <ticket id> <attachment id>",
+		Short:        "Print a signed attachment download URL that works without the access token until it expires",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.Server.SigningKey == "" {
+				return fmt.Errorf("signing key is required")
+			}
+
+			ticketID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("ticket id is invalid: %v", err)
+			}
+
+			attachmentID, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("attachment id is invalid: %v", err)
+			}
+
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			domain, err := findDomain(cmd, dbpool, args[0])
+			if err != nil {
+				return err
+			}
+
+			expires := time.Now().Add(cfg.Server.SignedURLTTL)
+			query := auth.NewSigner(cfg.Server.SigningKey).Sign(domain.ID, ticketID, attachmentID, expires)
+			fmt.Fprintf(cmd.OutOrStdout(), "%s%s?%s\n", strings.TrimSuffix(baseURL, "/"),
+				httpserver.AttachmentPath(domain.ID, ticketID, attachmentID), query.Encode())
+
+			return nil
+		},
+	}
+
+	c.Flags().StringVar(&cfg.Server.SigningKey, "signing-key", "", "secret key of signed attachment URLs, must match the API server key")
+	c.Flags().DurationVar(&cfg.Server.SignedURLTTL, "ttl", time.Hour, "lifetime of the URL")
+	c.Flags().StringVar(&baseURL, "base-url", "", "API base URL, only the path is printed if empty")
+
+	return c
+}
+
+// writeOutput creates the output file, or uses stdout for "-", and writes into it with the given function.
+// The partially written file is removed on error.
+func writeOutput(cmd *cobra.Command, output string, write func(w io.Writer) error) error {
+	if output == "-" {
+		return write(cmd.OutOrStdout())
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("create output: %v", err)
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(output)
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close output: %v", err)
+	}
+
+	return nil
+}
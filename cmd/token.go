@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/pkg/auth"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+)
+
+func tokenCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use:          "token",
+		Short:        "Manage API access tokens",
+		SilenceUsage: true,
+	}
+
+	c.AddCommand(tokenCreateCommand(cfg, log), tokenListCommand(cfg, log), tokenRevokeCommand(cfg, log))
+
+	return c
+}
+
+func tokenCreateCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	var domains []string
+
+	c := &cobra.Command{
+		Use:          "create <name>",
+		Short:        "Create an API token, the token is printed only once",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			var domainIDs []int64
+			for _, name := range domains {
+				domain, err := findDomain(cmd, dbpool, name)
+				if err != nil {
+					return err
+				}
+
+				domainIDs = append(domainIDs, domain.ID)
+			}
+
+			token, err := auth.GenerateToken()
+			if err != nil {
+				return fmt.Errorf("generate token: %v", err)
+			}
+
+			if _, err := dbpool.CreateToken(cmd.Context(), args[0], auth.HashToken(token), domainIDs); err != nil {
+				return fmt.Errorf("create token: %v", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), token)
+
+			return nil
+		},
+	}
+
+	c.Flags().StringSliceVar(&domains, "domain", nil, "domain name or ID the token is scoped to, all domains if not set")
+
+	return c
+}
+
+func tokenListCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "List API tokens",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			tokens, err := dbpool.Tokens(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("tokens: %v", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tDOMAINS\tCREATED\tREVOKED")
+			for _, t := range tokens {
+				scope := "all"
+				if len(t.DomainIDs) > 0 {
+					scope = strings.Trim(strings.Join(strings.Fields(fmt.Sprint(t.DomainIDs)), ","), "[]")
+				}
+
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", t.ID, t.Name, scope, formatTime(&t.CreatedAt), formatTime(t.RevokedAt))
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+func tokenRevokeCommand(cfg *config.Config, log *wlog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:          "revoke <name>",
+		Short:        "Revoke an API token",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbpool, err := db.New(cmd.Context(), log, cfg.DSN)
+			if err != nil {
+				return err
+			}
+
+			if err := dbpool.RevokeToken(cmd.Context(), args[0]); err != nil {
+				if errors.Is(err, db.ErrDBNoExists) {
+					return fmt.Errorf("active token %q not found", args[0])
+				}
+
+				return fmt.Errorf("revoke token: %v", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "revoked token %q\n", args[0])
+
+			return nil
+		},
+	}
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // S3 represents a configuration for accessing an S3 bucket.
 //
 // AccessKeyID is the access key for authenticating with AWS.
@@ -16,9 +18,77 @@ type S3 struct {
 	Bucket          string
 }
 
+// Filter represents a set of conditions a ticket has to match to be imported.
+// Zero values disable the corresponding condition.
+//
+// CreatedFrom, CreatedTo, UpdatedFrom and UpdatedTo limit the ticket creation and update time window.
+//
+// Statuses is the list of allowed ticket statuses.
+//
+// RequesterIDs and RequesterNames are the lists of allowed requesters.
+//
+// SkipSpam and SkipDeleted exclude tickets marked as spam or deleted.
+type Filter struct {
+	CreatedFrom    time.Time
+	CreatedTo      time.Time
+	UpdatedFrom    time.Time
+	UpdatedTo      time.Time
+	Statuses       []int64
+	RequesterIDs   []int64
+	RequesterNames []string
+	SkipSpam       bool
+	SkipDeleted    bool
+}
+
+// Server represents a configuration of the API server.
+//
+// Address is the address to listen on.
+//
+// Token is the master access token granting access to all domains.
+//
+// SigningKey is the secret key of signed attachment URLs, signed URLs are disabled if it is empty.
+//
+// SignedURLTTL is the lifetime of signed attachment URLs issued by the API.
+//
+// ShutdownTimeout is the time to wait for in-flight requests to complete on shutdown.
+//
+// TLSCert and TLSKey are the certificate and private key files, the server uses plain HTTP if they are empty.
+//
+// TLSClientCA is the CA file to verify client certificates, which authenticate requests instead of the access token.
+//
+// TLSClientDomains maps common names of client certificates to the domains they grant access to. Certificates
+// with other common names are rejected, unless TLSClientAllDomains grants them access to all domains.
+//
+// RateLimit and RateBurst are the sustained rate (requests per second) and the burst of requests per client,
+// the rate is unlimited if RateLimit is zero.
+//
+// IPRateLimit and IPRateBurst limit requests per remote IP address before authentication, so invalid tokens
+// and signatures can't be brute-forced, the rate is unlimited if IPRateLimit is zero.
+//
+// MaxDownloads is the cap of concurrent attachment and bundle downloads across all clients, unlimited if zero.
+//
+// DownloadBandwidth is the download bandwidth per client in bytes per second, unlimited if zero.
 type Server struct {
-	Address string
-	Token   string
+	Address      string
+	Token        string
+	SigningKey   string
+	SignedURLTTL time.Duration
+
+	ShutdownTimeout time.Duration
+
+	TLSCert     string
+	TLSKey      string
+	TLSClientCA string
+
+	TLSClientDomains    map[string][]int64
+	TLSClientAllDomains bool
+
+	RateLimit         float64
+	RateBurst         int
+	IPRateLimit       float64
+	IPRateBurst       int
+	MaxDownloads      int
+	DownloadBandwidth int64
 }
 
 // Config represents a configuration object that is used to store application settings.
@@ -31,7 +101,13 @@ type Server struct {
 //
 // DSN is the database connection string.
 //
+// MigrationsDir is the directory with database migrations, overriding migrations embedded into the binary.
+//
+// AutoMigrate enables applying pending migrations on startup instead of refusing to run.
+//
 // S3 is the configuration for accessing an S3 bucket. Refer to the documentation of the S3 type for more details.
+//
+// Filter is the set of conditions for imported tickets. Refer to the documentation of the Filter type for more details.
 type Config struct {
 	LogLevel      string
 	LogFile       string
@@ -40,13 +116,17 @@ type Config struct {
 	AttachmentDir string
 	Domain        string
 	DSN           string
+	MigrationsDir string
+	AutoMigrate   bool
 	S3            *S3
 	Server        *Server
+	Filter        *Filter
 }
 
 func New() *Config {
 	return &Config{
 		S3:     &S3{},
 		Server: &Server{},
+		Filter: &Filter{},
 	}
 }
@@ -0,0 +1,83 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE fresh.domain
+    ADD COLUMN language regconfig NOT NULL DEFAULT 'simple';
+
+ALTER TABLE fresh.ticket
+    ADD COLUMN search_vector tsvector;
+
+ALTER TABLE fresh.conversation
+    ADD COLUMN search_vector tsvector;
+
+CREATE FUNCTION fresh.domain_language(bigint) RETURNS regconfig
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT coalesce((SELECT language FROM fresh.domain WHERE id = $1), 'simple'::regconfig)
+$$;
+
+CREATE FUNCTION fresh.ticket_search_vector() RETURNS trigger
+    LANGUAGE plpgsql
+AS
+$$
+DECLARE
+    cfg regconfig := fresh.domain_language(NEW.domain_id);
+BEGIN
+    NEW.search_vector := setweight(to_tsvector(cfg, coalesce(NEW.subject, '')), 'A') ||
+                         setweight(to_tsvector(cfg, coalesce(NEW.description_text, '')), 'B');
+    RETURN NEW;
+END
+$$;
+
+CREATE FUNCTION fresh.conversation_search_vector() RETURNS trigger
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    NEW.search_vector := to_tsvector(fresh.domain_language(NEW.domain_id), coalesce(NEW.body_text, ''));
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER ticket_search_vector_tg
+    BEFORE INSERT OR UPDATE
+    ON fresh.ticket
+    FOR EACH ROW
+EXECUTE FUNCTION fresh.ticket_search_vector();
+
+CREATE TRIGGER conversation_search_vector_tg
+    BEFORE INSERT OR UPDATE
+    ON fresh.conversation
+    FOR EACH ROW
+EXECUTE FUNCTION fresh.conversation_search_vector();
+
+-- triggers compute vectors of the already imported rows
+UPDATE fresh.ticket SET search_vector = NULL;
+UPDATE fresh.conversation SET search_vector = NULL;
+
+CREATE INDEX ticket_search_vector_idx ON fresh.ticket USING gin (search_vector);
+CREATE INDEX conversation_search_vector_idx ON fresh.conversation USING gin (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX fresh.conversation_search_vector_idx;
+DROP INDEX fresh.ticket_search_vector_idx;
+
+DROP TRIGGER conversation_search_vector_tg ON fresh.conversation;
+DROP TRIGGER ticket_search_vector_tg ON fresh.ticket;
+
+DROP FUNCTION fresh.conversation_search_vector();
+DROP FUNCTION fresh.ticket_search_vector();
+DROP FUNCTION fresh.domain_language(bigint);
+
+ALTER TABLE fresh.conversation
+    DROP COLUMN search_vector;
+
+ALTER TABLE fresh.ticket
+    DROP COLUMN search_vector;
+
+ALTER TABLE fresh.domain
+    DROP COLUMN language;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE fresh.attachment
+    ADD COLUMN checksum varchar;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE fresh.attachment
+    DROP COLUMN checksum;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE fresh.api_token
+(
+    id         serial primary key,
+    name       varchar not null,
+    token_hash varchar not null,
+    domain_ids bigint[],
+    created_at timestamp default now(),
+    revoked_at timestamp
+);
+
+CREATE UNIQUE INDEX api_token_token_hash_idx ON fresh.api_token USING btree (token_hash);
+CREATE UNIQUE INDEX api_token_name_idx ON fresh.api_token USING btree (name) WHERE revoked_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE fresh.api_token;
+-- +goose StatementEnd
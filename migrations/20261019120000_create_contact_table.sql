@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE fresh.contact
+(
+    row_id             serial,
+    aws_key            varchar,
+    domain_id          bigint,
+    id                 bigint,
+    name               varchar,
+    email              varchar,
+    other_emails       varchar[],
+    phone              varchar,
+    mobile             varchar,
+    twitter_id         varchar,
+    unique_external_id varchar,
+    company_id         bigint,
+    other_companies    jsonb,
+    job_title          varchar,
+    description        text,
+    address            text,
+    language           varchar,
+    time_zone          varchar,
+    active             boolean,
+    deleted            boolean,
+    view_all_tickets   boolean,
+    tags               varchar[],
+    custom_fields      jsonb,
+    created_at         timestamp,
+    updated_at         timestamp,
+    imported_at        timestamp default now()
+);
+
+CREATE UNIQUE INDEX contact_id_idx ON fresh.contact USING btree (domain_id, id);
+CREATE INDEX ticket_requester_id_idx ON fresh.ticket USING btree (domain_id, requester_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX fresh.ticket_requester_id_idx;
+DROP TABLE fresh.contact;
+-- +goose StatementEnd
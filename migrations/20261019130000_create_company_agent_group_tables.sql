@@ -0,0 +1,79 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE fresh.company
+(
+    row_id        serial,
+    aws_key       varchar,
+    domain_id     bigint,
+    id            bigint,
+    name          varchar,
+    description   text,
+    note          text,
+    domains       varchar[],
+    health_score  varchar,
+    account_tier  varchar,
+    renewal_date  timestamp,
+    industry      varchar,
+    custom_fields jsonb,
+    created_at    timestamp,
+    updated_at    timestamp,
+    imported_at   timestamp default now()
+);
+
+CREATE TABLE fresh.agent
+(
+    row_id         serial,
+    aws_key        varchar,
+    domain_id      bigint,
+    id             bigint,
+    name           varchar,
+    email          varchar,
+    phone          varchar,
+    mobile         varchar,
+    job_title      varchar,
+    language       varchar,
+    time_zone      varchar,
+    active         boolean,
+    available      boolean,
+    occasional     boolean,
+    ticket_scope   bigint,
+    type           varchar,
+    group_ids      bigint[],
+    role_ids       bigint[],
+    signature      text,
+    last_active_at timestamp,
+    created_at     timestamp,
+    updated_at     timestamp,
+    imported_at    timestamp default now()
+);
+
+CREATE TABLE fresh.agent_group
+(
+    row_id             serial,
+    aws_key            varchar,
+    domain_id          bigint,
+    id                 bigint,
+    name               varchar,
+    description        text,
+    escalate_to        bigint,
+    unassigned_for     varchar,
+    business_hour_id   bigint,
+    group_type         varchar,
+    agent_ids          bigint[],
+    auto_ticket_assign jsonb,
+    created_at         timestamp,
+    updated_at         timestamp,
+    imported_at        timestamp default now()
+);
+
+CREATE UNIQUE INDEX company_id_idx ON fresh.company USING btree (domain_id, id);
+CREATE UNIQUE INDEX agent_id_idx ON fresh.agent USING btree (domain_id, id);
+CREATE UNIQUE INDEX agent_group_id_idx ON fresh.agent_group USING btree (domain_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE fresh.agent_group;
+DROP TABLE fresh.agent;
+DROP TABLE fresh.company;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE fresh.ticket_field
+(
+    row_id               serial,
+    aws_key              varchar,
+    domain_id            bigint,
+    id                   bigint,
+    name                 varchar,
+    label                varchar,
+    label_for_customers  varchar,
+    description          text,
+    type                 varchar,
+    position             bigint,
+    "default"            boolean,
+    required_for_agents  boolean,
+    choices              jsonb,
+    nested_ticket_fields jsonb,
+    created_at           timestamp,
+    updated_at           timestamp,
+    imported_at          timestamp default now()
+);
+
+CREATE UNIQUE INDEX ticket_field_id_idx ON fresh.ticket_field USING btree (domain_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE fresh.ticket_field;
+-- +goose StatementEnd
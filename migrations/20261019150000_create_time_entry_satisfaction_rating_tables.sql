@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE fresh.time_entry
+(
+    row_id             serial,
+    aws_key            varchar,
+    domain_id          bigint,
+    id                 bigint,
+    ticket_id          bigint,
+    agent_id           bigint,
+    company_id         bigint,
+    billable           boolean,
+    note               text,
+    time_spent         varchar,
+    time_spent_minutes bigint,
+    timer_running      boolean,
+    executed_at        timestamp,
+    start_time         timestamp,
+    created_at         timestamp,
+    updated_at         timestamp,
+    imported_at        timestamp default now()
+);
+
+CREATE TABLE fresh.satisfaction_rating
+(
+    row_id      serial,
+    aws_key     varchar,
+    domain_id   bigint,
+    id          bigint,
+    ticket_id   bigint,
+    survey_id   bigint,
+    user_id     bigint,
+    agent_id    bigint,
+    group_id    bigint,
+    feedback    text,
+    ratings     jsonb,
+    created_at  timestamp,
+    updated_at  timestamp,
+    imported_at timestamp default now()
+);
+
+CREATE UNIQUE INDEX time_entry_id_idx ON fresh.time_entry USING btree (domain_id, id);
+CREATE INDEX time_entry_ticket_id_idx ON fresh.time_entry USING btree (domain_id, ticket_id);
+CREATE UNIQUE INDEX satisfaction_rating_id_idx ON fresh.satisfaction_rating USING btree (domain_id, id);
+CREATE INDEX satisfaction_rating_ticket_id_idx ON fresh.satisfaction_rating USING btree (domain_id, ticket_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE fresh.satisfaction_rating;
+DROP TABLE fresh.time_entry;
+-- +goose StatementEnd
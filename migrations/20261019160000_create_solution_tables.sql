@@ -0,0 +1,96 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE fresh.solution_category
+(
+    row_id             serial,
+    aws_key            varchar,
+    domain_id          bigint,
+    id                 bigint,
+    name               varchar,
+    description        text,
+    visible_in_portals bigint[],
+    created_at         timestamp,
+    updated_at         timestamp,
+    imported_at        timestamp default now()
+);
+
+CREATE TABLE fresh.solution_folder
+(
+    row_id              serial,
+    aws_key             varchar,
+    domain_id           bigint,
+    id                  bigint,
+    category_id         bigint,
+    parent_folder_id    bigint,
+    name                varchar,
+    description         text,
+    visibility          bigint,
+    company_ids         bigint[],
+    contact_segment_ids bigint[],
+    company_segment_ids bigint[],
+    created_at          timestamp,
+    updated_at          timestamp,
+    imported_at         timestamp default now()
+);
+
+CREATE TABLE fresh.solution_article
+(
+    row_id           serial,
+    aws_key          varchar,
+    domain_id        bigint,
+    id               bigint,
+    category_id      bigint,
+    folder_id        bigint,
+    agent_id         bigint,
+    type             bigint,
+    status           bigint,
+    title            varchar,
+    description      text,
+    description_text text,
+    tags             varchar[],
+    seo_data         jsonb,
+    attachment_ids   bigint[],
+    thumbs_up        bigint,
+    thumbs_down      bigint,
+    hits             bigint,
+    created_at       timestamp,
+    updated_at       timestamp,
+    imported_at      timestamp default now(),
+    search_vector    tsvector
+);
+
+CREATE FUNCTION fresh.solution_article_search_vector() RETURNS trigger
+    LANGUAGE plpgsql
+AS
+$$
+DECLARE
+    cfg regconfig := fresh.domain_language(NEW.domain_id);
+BEGIN
+    NEW.search_vector := setweight(to_tsvector(cfg, coalesce(NEW.title, '')), 'A') ||
+                         setweight(to_tsvector(cfg, array_to_string(coalesce(NEW.tags, '{}'), ' ')), 'B') ||
+                         setweight(to_tsvector(cfg, coalesce(NEW.description_text, '')), 'C');
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER solution_article_search_vector_tg
+    BEFORE INSERT OR UPDATE
+    ON fresh.solution_article
+    FOR EACH ROW
+EXECUTE FUNCTION fresh.solution_article_search_vector();
+
+CREATE UNIQUE INDEX solution_category_id_idx ON fresh.solution_category USING btree (domain_id, id);
+CREATE UNIQUE INDEX solution_folder_id_idx ON fresh.solution_folder USING btree (domain_id, id);
+CREATE INDEX solution_folder_category_id_idx ON fresh.solution_folder USING btree (domain_id, category_id);
+CREATE UNIQUE INDEX solution_article_id_idx ON fresh.solution_article USING btree (domain_id, id);
+CREATE INDEX solution_article_folder_id_idx ON fresh.solution_article USING btree (domain_id, folder_id);
+CREATE INDEX solution_article_search_vector_idx ON fresh.solution_article USING gin (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE fresh.solution_article;
+DROP FUNCTION fresh.solution_article_search_vector();
+DROP TABLE fresh.solution_folder;
+DROP TABLE fresh.solution_category;
+-- +goose StatementEnd
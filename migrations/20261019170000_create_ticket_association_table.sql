@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE fresh.ticket_association
+(
+    row_id           serial,
+    aws_key          varchar,
+    domain_id        bigint,
+    parent_id        bigint,
+    child_id         bigint,
+    association_type bigint,
+    imported_at      timestamp default now()
+);
+
+CREATE UNIQUE INDEX ticket_association_idx ON fresh.ticket_association USING btree (domain_id, parent_id, child_id);
+CREATE INDEX ticket_association_child_id_idx ON fresh.ticket_association USING btree (domain_id, child_id);
+
+-- links of the already imported tickets from the raw Freshdesk JSON: parent (1) and tracker (3) tickets list
+-- their child and related tickets, child (2) and related (4) tickets list their parent or tracker ticket
+INSERT INTO fresh.ticket_association (aws_key, domain_id, parent_id, child_id, association_type)
+SELECT r.aws_key,
+       r.domain_id,
+       CASE WHEN (r.ticket ->> 'association_type')::bigint IN (1, 3) THEN r.ticket_id ELSE l.id::bigint END,
+       CASE WHEN (r.ticket ->> 'association_type')::bigint IN (1, 3) THEN l.id::bigint ELSE r.ticket_id END,
+       CASE WHEN (r.ticket ->> 'association_type')::bigint IN (1, 2) THEN 2 ELSE 4 END
+FROM fresh.ticket_raw r
+         CROSS JOIN LATERAL jsonb_array_elements_text(
+        CASE
+            WHEN jsonb_typeof(r.ticket -> 'associated_tickets_list') = 'array'
+                THEN r.ticket -> 'associated_tickets_list'
+            ELSE '[]'::jsonb END) l(id)
+WHERE (r.ticket ->> 'association_type')::bigint IN (1, 2, 3, 4)
+ON CONFLICT DO NOTHING;
+
+UPDATE fresh.ticket t
+SET parent_id = a.parent_id
+FROM fresh.ticket_association a
+WHERE t.domain_id = a.domain_id
+  AND t.id = a.child_id;
+
+UPDATE fresh.conversation c
+SET parent_id = a.parent_id
+FROM fresh.ticket_association a
+WHERE c.domain_id = a.domain_id
+  AND c.ticket_id = a.child_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE fresh.conversation SET parent_id = NULL WHERE parent_id IS NOT NULL;
+UPDATE fresh.ticket SET parent_id = NULL WHERE parent_id IS NOT NULL;
+
+DROP TABLE fresh.ticket_association;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- escapes HTML special characters, so search highlights are safe HTML where only ts_headline markers are tags
+CREATE FUNCTION fresh.html_escape(text) RETURNS text
+    LANGUAGE sql
+    IMMUTABLE
+AS
+$$
+SELECT replace(replace(replace(replace(replace($1, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''',
+               '&#39;')
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION fresh.html_escape(text);
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the ticket JSON exactly as imported, jsonb normalizes keys order, duplicate keys, whitespace and numbers
+ALTER TABLE fresh.ticket_raw
+    ADD COLUMN ticket_text text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE fresh.ticket_raw
+    DROP COLUMN ticket_text;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the ticket list is sorted by (created_at, id), the index is scanned forward for the descending order
+-- and backward for the ascending one
+DROP INDEX fresh.ticket_created_at_idx;
+CREATE INDEX ticket_created_at_idx ON fresh.ticket USING btree (domain_id, created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX fresh.ticket_created_at_idx;
+CREATE INDEX ticket_created_at_idx ON fresh.ticket USING btree (domain_id, created_at DESC);
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+// Package migrations contains database schema migrations embedded into the binary.
+package migrations
+
+import "embed"
+
+// FS contains goose SQL migrations of the fresh schema.
+//
+//go:embed *.sql
+var FS embed.FS
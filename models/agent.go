@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// Agent represents a row in the fresh.agent table. Tickets refer to agents by responder_id,
+// conversations by user_id. The Freshdesk export nests the agent's name and email in the contact object,
+// they are stored in the agent row.
+type Agent struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ID           int64         `json:"id" db:"id"`
+	Contact      *AgentContact `json:"contact,omitempty" db:"-"`
+	Available    bool          `json:"available,omitempty" db:"available"`
+	Occasional   bool          `json:"occasional,omitempty" db:"occasional"`
+	TicketScope  int64         `json:"ticket_scope,omitempty" db:"ticket_scope"`
+	Type         string        `json:"type,omitempty" db:"type"`
+	GroupIDs     []int64       `json:"group_ids,omitempty" db:"group_ids"`
+	RoleIDs      []int64       `json:"role_ids,omitempty" db:"role_ids"`
+	Signature    string        `json:"signature,omitempty" db:"signature"`
+	LastActiveAt *time.Time    `json:"last_active_at,omitempty" db:"last_active_at"`
+	CreatedAt    *time.Time    `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt    *time.Time    `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// AgentContact represents the contact details of the agent.
+type AgentContact struct {
+	Name     string `json:"name,omitempty" db:"name"`
+	Email    string `json:"email,omitempty" db:"email"`
+	Phone    string `json:"phone,omitempty" db:"phone"`
+	Mobile   string `json:"mobile,omitempty" db:"mobile"`
+	JobTitle string `json:"job_title,omitempty" db:"job_title"`
+	Language string `json:"language,omitempty" db:"language"`
+	TimeZone string `json:"time_zone,omitempty" db:"time_zone"`
+	Active   bool   `json:"active,omitempty" db:"active"`
+}
@@ -18,4 +18,7 @@ type Attachment struct {
 	ThumbURL    string    `json:"thumb_url" db:"thumb_url"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	Checksum    string    `json:"checksum,omitempty" db:"checksum"`
+
+	DownloadURL string `json:"download_url,omitempty" db:"-"`
 }
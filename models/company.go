@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// Company represents a row in the fresh.company table. Tickets and contacts refer to companies by company_id.
+type Company struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ID           int64      `json:"id" db:"id"`
+	Name         string     `json:"name,omitempty" db:"name"`
+	Description  string     `json:"description,omitempty" db:"description"`
+	Note         string     `json:"note,omitempty" db:"note"`
+	Domains      []string   `json:"domains,omitempty" db:"domains"`
+	HealthScore  string     `json:"health_score,omitempty" db:"health_score"`
+	AccountTier  string     `json:"account_tier,omitempty" db:"account_tier"`
+	RenewalDate  *time.Time `json:"renewal_date,omitempty" db:"renewal_date"`
+	Industry     string     `json:"industry,omitempty" db:"industry"`
+	CustomFields any        `json:"custom_fields,omitempty" db:"custom_fields"`
+	CreatedAt    *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
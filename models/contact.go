@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// Contact represents a row in the fresh.contact table. Tickets refer to contacts by requester_id.
+type Contact struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ID               int64      `json:"id" db:"id"`
+	Name             string     `json:"name,omitempty" db:"name"`
+	Email            string     `json:"email,omitempty" db:"email"`
+	OtherEmails      []string   `json:"other_emails,omitempty" db:"other_emails"`
+	Phone            string     `json:"phone,omitempty" db:"phone"`
+	Mobile           string     `json:"mobile,omitempty" db:"mobile"`
+	TwitterID        string     `json:"twitter_id,omitempty" db:"twitter_id"`
+	UniqueExternalID string     `json:"unique_external_id,omitempty" db:"unique_external_id"`
+	CompanyID        int64      `json:"company_id,omitempty" db:"company_id"`
+	OtherCompanies   any        `json:"other_companies,omitempty" db:"other_companies"`
+	JobTitle         string     `json:"job_title,omitempty" db:"job_title"`
+	Description      string     `json:"description,omitempty" db:"description"`
+	Address          string     `json:"address,omitempty" db:"address"`
+	Language         string     `json:"language,omitempty" db:"language"`
+	TimeZone         string     `json:"time_zone,omitempty" db:"time_zone"`
+	Active           bool       `json:"active,omitempty" db:"active"`
+	Deleted          bool       `json:"deleted,omitempty" db:"deleted"`
+	ViewAllTickets   bool       `json:"view_all_tickets,omitempty" db:"view_all_tickets"`
+	Tags             []string   `json:"tags,omitempty" db:"tags"`
+	CustomFields     any        `json:"custom_fields,omitempty" db:"custom_fields"`
+	CreatedAt        *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
@@ -9,6 +9,7 @@ type Conversation struct {
 
 	Id                   int64         `json:"id" db:"id"`
 	TicketID             int64         `json:"ticket_id" db:"ticket_id"`
+	ParentID             int64         `json:"parent_id,omitempty" db:"parent_id"`
 	Body                 string        `json:"body" db:"body"`
 	BodyText             string        `json:"body_text" db:"body_text"`
 	Incoming             bool          `json:"incoming" db:"incoming"`
@@ -36,4 +37,7 @@ type Conversation struct {
 	CreatedAt            time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time     `json:"updated_at" db:"updated_at"`
 	Attachments          []*Attachment `json:"attachments" db:"attachments"`
+	AttachmentIDs        []int64       `json:"-" db:"attachment_ids"`
+
+	UserName string `json:"user_name,omitempty" db:"-"`
 }
@@ -1,6 +1,24 @@
 package models
 
+import "time"
+
 type Domain struct {
-	ID   int64  `json:"id" db:"id"`
-	Name string `json:"name" db:"name"`
+	ID       int64  `json:"id" db:"id"`
+	Name     string `json:"name" db:"name"`
+	Language string `json:"language" db:"language"`
+}
+
+// DomainStats represents the summary of data imported for a domain
+type DomainStats struct {
+	Tickets         int64      `json:"tickets"`
+	RawTickets      int64      `json:"raw_tickets"`
+	Conversations   int64      `json:"conversations"`
+	Attachments     int64      `json:"attachments"`
+	AttachmentBytes int64      `json:"attachment_bytes"`
+	Contacts        int64      `json:"contacts"`
+	Companies       int64      `json:"companies"`
+	Agents          int64      `json:"agents"`
+	Groups          int64      `json:"groups"`
+	Articles        int64      `json:"articles"`
+	LastImportedAt  *time.Time `json:"last_imported_at,omitempty"`
 }
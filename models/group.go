@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Group represents a row in the fresh.agent_group table. Tickets refer to groups by group_id.
+type Group struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ID               int64      `json:"id" db:"id"`
+	Name             string     `json:"name,omitempty" db:"name"`
+	Description      string     `json:"description,omitempty" db:"description"`
+	EscalateTo       int64      `json:"escalate_to,omitempty" db:"escalate_to"`
+	UnassignedFor    string     `json:"unassigned_for,omitempty" db:"unassigned_for"`
+	BusinessHourID   int64      `json:"business_hour_id,omitempty" db:"business_hour_id"`
+	GroupType        string     `json:"group_type,omitempty" db:"group_type"`
+	AgentIDs         []int64    `json:"agent_ids,omitempty" db:"agent_ids"`
+	AutoTicketAssign any        `json:"auto_ticket_assign,omitempty" db:"auto_ticket_assign"`
+	CreatedAt        *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
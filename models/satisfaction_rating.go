@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// SatisfactionRating represents a row in the fresh.satisfaction_rating table: the CSAT survey response
+// of the ticket. Ratings map survey questions to the rating values, e.g. {"default_question": 103}.
+type SatisfactionRating struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ID        int64      `json:"id" db:"id"`
+	TicketID  int64      `json:"ticket_id" db:"ticket_id"`
+	SurveyID  int64      `json:"survey_id,omitempty" db:"survey_id"`
+	UserID    int64      `json:"user_id,omitempty" db:"user_id"`
+	AgentID   int64      `json:"agent_id,omitempty" db:"agent_id"`
+	GroupID   int64      `json:"group_id,omitempty" db:"group_id"`
+	Feedback  string     `json:"feedback,omitempty" db:"feedback"`
+	Ratings   any        `json:"ratings,omitempty" db:"ratings"`
+	CreatedAt *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+
+	AgentName string `json:"agent_name,omitempty" db:"-"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SearchResult represents a ticket found by the full-text search.
+// Highlights contain HTML-escaped fragments of the matched text with terms wrapped in <mark> tags.
+type SearchResult struct {
+	TicketID              int64      `json:"ticket_id"`
+	Subject               string     `json:"subject"`
+	Status                int64      `json:"status"`
+	RequesterName         string     `json:"requester_name"`
+	CreatedAt             *time.Time `json:"created_at,omitempty"`
+	Rank                  float32    `json:"rank"`
+	SubjectHighlight      string     `json:"subject_highlight,omitempty"`
+	DescriptionHighlight  string     `json:"description_highlight,omitempty"`
+	ConversationHighlight string     `json:"conversation_highlight,omitempty"`
+}
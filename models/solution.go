@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+)
+
+// Freshdesk statuses of solution articles.
+const (
+	ArticleStatusDraft     = 1
+	ArticleStatusPublished = 2
+)
+
+// SolutionCategory represents a row in the fresh.solution_category table: the top level of the knowledge base.
+type SolutionCategory struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ID               int64      `json:"id" db:"id"`
+	Name             string     `json:"name,omitempty" db:"name"`
+	Description      string     `json:"description,omitempty" db:"description"`
+	VisibleInPortals []int64    `json:"visible_in_portals,omitempty" db:"visible_in_portals"`
+	CreatedAt        *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+
+	Folders []*SolutionFolder `json:"folders,omitempty" db:"-"`
+}
+
+// SolutionFolder represents a row in the fresh.solution_folder table. Folders belong to a category
+// and may be nested into a parent folder.
+type SolutionFolder struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ID                int64      `json:"id" db:"id"`
+	CategoryID        int64      `json:"category_id,omitempty" db:"category_id"`
+	ParentFolderID    int64      `json:"parent_folder_id,omitempty" db:"parent_folder_id"`
+	Name              string     `json:"name,omitempty" db:"name"`
+	Description       string     `json:"description,omitempty" db:"description"`
+	Visibility        int64      `json:"visibility,omitempty" db:"visibility"`
+	CompanyIDs        []int64    `json:"company_ids,omitempty" db:"company_ids"`
+	ContactSegmentIDs []int64    `json:"contact_segment_ids,omitempty" db:"contact_segment_ids"`
+	CompanySegmentIDs []int64    `json:"company_segment_ids,omitempty" db:"company_segment_ids"`
+	CreatedAt         *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+
+	ArticlesCount int64 `json:"articles_count" db:"-"`
+}
+
+// SolutionArticle represents a row in the fresh.solution_article table. Description is the HTML body
+// of the article, attachments are stored in the fresh.attachment table and referred by attachment_ids.
+type SolutionArticle struct {
+	RowID         int64     `json:"row_id" db:"row_id"`
+	ImportedAt    time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey        string    `json:"aws_key" db:"aws_key"`
+	DomainID      int64     `json:"domain_id" db:"domain_id"`
+	AttachmentIDs []int64   `json:"-" db:"attachment_ids"`
+
+	ID              int64           `json:"id" db:"id"`
+	CategoryID      int64           `json:"category_id,omitempty" db:"category_id"`
+	FolderID        int64           `json:"folder_id,omitempty" db:"folder_id"`
+	AgentID         int64           `json:"agent_id,omitempty" db:"agent_id"`
+	Type            int64           `json:"type,omitempty" db:"type"`
+	Status          int64           `json:"status,omitempty" db:"status"`
+	Title           string          `json:"title,omitempty" db:"title"`
+	Description     string          `json:"description,omitempty" db:"description"`
+	DescriptionText string          `json:"description_text,omitempty" db:"description_text"`
+	Tags            []string        `json:"tags,omitempty" db:"tags"`
+	SEOData         *ArticleSEOData `json:"seo_data,omitempty" db:"seo_data"`
+	ThumbsUp        int64           `json:"thumbs_up,omitempty" db:"thumbs_up"`
+	ThumbsDown      int64           `json:"thumbs_down,omitempty" db:"thumbs_down"`
+	Hits            int64           `json:"hits,omitempty" db:"hits"`
+	CreatedAt       *time.Time      `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt       *time.Time      `json:"updated_at,omitempty" db:"updated_at"`
+	Attachments     []*Attachment   `json:"attachments,omitempty" db:"-"`
+
+	AgentName string `json:"agent_name,omitempty" db:"-"`
+}
+
+// ArticleSEOData is the search engine metadata of the article.
+type ArticleSEOData struct {
+	MetaTitle       string `json:"meta_title,omitempty"`
+	MetaDescription string `json:"meta_description,omitempty"`
+	MetaKeywords    any    `json:"meta_keywords,omitempty"`
+}
+
+// ArticleSearchResult represents an article found by the full-text search.
+// Highlights contain HTML-escaped fragments of the matched text with terms wrapped in <mark> tags.
+type ArticleSearchResult struct {
+	ArticleID            int64      `json:"article_id"`
+	FolderID             int64      `json:"folder_id,omitempty"`
+	CategoryID           int64      `json:"category_id,omitempty"`
+	Title                string     `json:"title"`
+	Status               int64      `json:"status"`
+	UpdatedAt            *time.Time `json:"updated_at,omitempty"`
+	Rank                 float32    `json:"rank"`
+	TitleHighlight       string     `json:"title_highlight,omitempty"`
+	DescriptionHighlight string     `json:"description_highlight,omitempty"`
+}
@@ -6,7 +6,8 @@ import (
 
 // Ticket represents a row in the fresh.ticket table
 type Ticket struct {
-	Raw []byte `json:"-" db:"-"`
+	Raw           []byte  `json:"-" db:"-"`
+	AttachmentIDs []int64 `json:"-" db:"attachment_ids"`
 
 	RowID      int64     `json:"row_id" db:"row_id"`
 	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
@@ -16,6 +17,7 @@ type Ticket struct {
 	RequesterName string `json:"requester_name" db:"requester_name"`
 
 	ID                   int64           `json:"id,omitempty" db:"id"`
+	ParentID             int64           `json:"parent_id,omitempty" db:"parent_id"`
 	Archived             bool            `json:"archived,omitempty" db:"archived"`
 	Meta                 any             `json:"meta,omitempty" db:"meta"`
 	Name                 string          `json:"name,omitempty" db:"name"`
@@ -56,7 +58,28 @@ type Ticket struct {
 	Attachments          []*Attachment   `json:"attachments,omitempty" db:"attachments"`
 	Conversations        []*Conversation `json:"conversations,omitempty" db:"conversations"`
 	AssociationType      int64           `json:"association_type,omitempty" db:"association_type"`
+	AssociatedTickets    []int64         `json:"associated_tickets_list,omitempty" db:"-"`
 	SourceAdditionalInfo string          `json:"source_additional_info,omitempty" db:"source_additional_info"`
 	SupportEmail         string          `json:"support_email,omitempty" db:"support_email"`
 	FormID               int64           `json:"form_id,omitempty" db:"form_id"`
+
+	StatusName   string `json:"status_name,omitempty" db:"-"`
+	PriorityName string `json:"priority_name,omitempty" db:"-"`
+	SourceName   string `json:"source_name,omitempty" db:"-"`
+
+	CompanyName   string `json:"company_name,omitempty" db:"-"`
+	ResponderName string `json:"responder_name,omitempty" db:"-"`
+	GroupName     string `json:"group_name,omitempty" db:"-"`
+
+	// CustomFieldValues are the custom fields decoded with the imported ticket field definitions.
+	CustomFieldValues []*CustomFieldValue `json:"custom_field_values,omitempty" db:"-"`
+
+	// Requester is the imported contact of the requester, if any.
+	Requester *Contact `json:"requester,omitempty" db:"-"`
+
+	// LinkedTickets are the parent and child, tracker and related tickets of the ticket.
+	LinkedTickets []*LinkedTicket `json:"linked_tickets,omitempty" db:"-"`
+
+	TimeEntries         []*TimeEntry          `json:"time_entries,omitempty" db:"-"`
+	SatisfactionRatings []*SatisfactionRating `json:"satisfaction_ratings,omitempty" db:"-"`
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Freshdesk association types of tickets.
+const (
+	AssociationParent  = 1
+	AssociationChild   = 2
+	AssociationTracker = 3
+	AssociationRelated = 4
+)
+
+// TicketAssociation represents a row in the fresh.ticket_association table: the link of the child ticket
+// to its parent ticket (association_type 2), or of the related ticket to its tracker ticket (association_type 4).
+type TicketAssociation struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ParentID        int64 `json:"parent_id" db:"parent_id"`
+	ChildID         int64 `json:"child_id" db:"child_id"`
+	AssociationType int64 `json:"association_type" db:"association_type"`
+}
+
+// LinkedTicket is a ticket linked to another one. Relation is the role of the linked ticket:
+// parent, child, tracker or related.
+type LinkedTicket struct {
+	ID         int64  `json:"id"`
+	Relation   string `json:"relation"`
+	Subject    string `json:"subject,omitempty"`
+	Status     int64  `json:"status,omitempty"`
+	StatusName string `json:"status_name,omitempty"`
+	Imported   bool   `json:"imported"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// TicketField represents a row in the fresh.ticket_field table: the definition of a ticket field.
+// Custom fields of tickets are keyed by the field name, e.g. cf_order_number. Choices are stored as exported,
+// their shape depends on the field type. Levels of nested dropdowns below the first one are described
+// by NestedTicketFields, their values are keyed by the nested field names.
+type TicketField struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ID                 int64                `json:"id" db:"id"`
+	Name               string               `json:"name" db:"name"`
+	Label              string               `json:"label" db:"label"`
+	LabelForCustomers  string               `json:"label_for_customers,omitempty" db:"label_for_customers"`
+	Description        string               `json:"description,omitempty" db:"description"`
+	Type               string               `json:"type" db:"type"`
+	Position           int64                `json:"position" db:"position"`
+	Default            bool                 `json:"default" db:"default"`
+	RequiredForAgents  bool                 `json:"required_for_agents,omitempty" db:"required_for_agents"`
+	Choices            any                  `json:"choices,omitempty" db:"choices"`
+	NestedTicketFields []*NestedTicketField `json:"nested_ticket_fields,omitempty" db:"nested_ticket_fields"`
+	CreatedAt          *time.Time           `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt          *time.Time           `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// NestedTicketField represents a level of the nested dropdown field.
+type NestedTicketField struct {
+	ID            int64  `json:"id,omitempty"`
+	Name          string `json:"name"`
+	Label         string `json:"label"`
+	LabelInPortal string `json:"label_in_portal,omitempty"`
+	Level         int64  `json:"level"`
+}
+
+// CustomFieldValue represents a ticket custom field decoded with its definition.
+// Type is empty if the field is not defined.
+type CustomFieldValue struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type,omitempty"`
+	Value any    `json:"value"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// TimeEntry represents a row in the fresh.time_entry table: time an agent spent on the ticket.
+// TimeSpent is the exported "hh:mm" duration, TimeSpentMinutes is the same duration in minutes.
+type TimeEntry struct {
+	RowID      int64     `json:"row_id" db:"row_id"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+	AWSKey     string    `json:"aws_key" db:"aws_key"`
+	DomainID   int64     `json:"domain_id" db:"domain_id"`
+
+	ID               int64      `json:"id" db:"id"`
+	TicketID         int64      `json:"ticket_id" db:"ticket_id"`
+	AgentID          int64      `json:"agent_id,omitempty" db:"agent_id"`
+	CompanyID        int64      `json:"company_id,omitempty" db:"company_id"`
+	Billable         bool       `json:"billable" db:"billable"`
+	Note             string     `json:"note,omitempty" db:"note"`
+	TimeSpent        string     `json:"time_spent,omitempty" db:"time_spent"`
+	TimeSpentMinutes int64      `json:"time_spent_minutes" db:"time_spent_minutes"`
+	TimerRunning     bool       `json:"timer_running,omitempty" db:"timer_running"`
+	ExecutedAt       *time.Time `json:"executed_at,omitempty" db:"executed_at"`
+	StartTime        *time.Time `json:"start_time,omitempty" db:"start_time"`
+	CreatedAt        *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+
+	AgentName string `json:"agent_name,omitempty" db:"-"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Token represents a row in the fresh.api_token table. The token itself is never stored, only its hash.
+type Token struct {
+	ID        int64      `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	DomainIDs []int64    `json:"domain_ids,omitempty" db:"domain_ids"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// Allows reports whether the token grants access to the domain. A token without domains grants access to all of them.
+func (t *Token) Allows(domain int64) bool {
+	return len(t.DomainIDs) == 0 || slices.Contains(t.DomainIDs, domain)
+}
@@ -0,0 +1,21 @@
+package models
+
+import "testing"
+
+func TestTokenAllows(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		domains []int64
+		domain  int64
+		want    bool
+	}{
+		{name: "unscoped", domain: 1, want: true},
+		{name: "scoped to the domain", domains: []int64{1, 2}, domain: 2, want: true},
+		{name: "scoped to other domains", domains: []int64{1, 2}, domain: 3},
+	} {
+		token := &Token{Name: tt.name, DomainIDs: tt.domains}
+		if got := token.Allows(tt.domain); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
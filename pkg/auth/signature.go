@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// ExpiresParam is the query parameter of the signed URL expiration time in Unix seconds.
+	ExpiresParam = "expires"
+
+	// SignatureParam is the query parameter of the signed URL signature.
+	SignatureParam = "signature"
+)
+
+// Signer signs attachment download URLs with HMAC-SHA256. A signature is bound
+// to the domain, ticket and attachment IDs and the expiration time.
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key string) *Signer {
+	return &Signer{key: []byte(key)}
+}
+
+// Sign returns query parameters granting access to the attachment until the expiration time.
+func (s *Signer) Sign(domain, ticket, attachment int64, expires time.Time) url.Values {
+	exp := expires.Unix()
+
+	return url.Values{
+		ExpiresParam:   {strconv.FormatInt(exp, 10)},
+		SignatureParam: {s.signature(domain, ticket, attachment, exp)},
+	}
+}
+
+// Verify reports whether the query parameters contain a valid signature of the attachment,
+// which is not expired at the given time.
+func (s *Signer) Verify(domain, ticket, attachment int64, query url.Values, now time.Time) bool {
+	exp, err := strconv.ParseInt(query.Get(ExpiresParam), 10, 64)
+	if err != nil || now.Unix() > exp {
+		return false
+	}
+
+	expected := s.signature(domain, ticket, attachment, exp)
+
+	return hmac.Equal([]byte(expected), []byte(query.Get(SignatureParam)))
+}
+
+func (s *Signer) signature(domain, ticket, attachment, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%d/%d/%d/%d", domain, ticket, attachment, expires)
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSignerVerify(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewSigner("key")
+	query := signer.Sign(1, 2, 3, now.Add(time.Hour))
+	for _, tt := range []struct {
+		name                       string
+		signer                     *Signer
+		domain, ticket, attachment int64
+		query                      url.Values
+		now                        time.Time
+		want                       bool
+	}{
+		{name: "valid", signer: signer, domain: 1, ticket: 2, attachment: 3, query: query, now: now, want: true},
+		{name: "at expiration", signer: signer, domain: 1, ticket: 2, attachment: 3, query: query,
+			now: now.Add(time.Hour), want: true},
+		{name: "expired", signer: signer, domain: 1, ticket: 2, attachment: 3, query: query,
+			now: now.Add(time.Hour + time.Second)},
+		{name: "other domain", signer: signer, domain: 4, ticket: 2, attachment: 3, query: query, now: now},
+		{name: "other ticket", signer: signer, domain: 1, ticket: 4, attachment: 3, query: query, now: now},
+		{name: "other attachment", signer: signer, domain: 1, ticket: 2, attachment: 4, query: query, now: now},
+		{name: "other key", signer: NewSigner("other"), domain: 1, ticket: 2, attachment: 3, query: query, now: now},
+		{name: "extended expiration", signer: signer, domain: 1, ticket: 2, attachment: 3, now: now,
+			query: url.Values{ExpiresParam: {"9999999999"}, SignatureParam: query[SignatureParam]}},
+		{name: "missing signature", signer: signer, domain: 1, ticket: 2, attachment: 3, now: now,
+			query: url.Values{ExpiresParam: query[ExpiresParam]}},
+		{name: "malformed expiration", signer: signer, domain: 1, ticket: 2, attachment: 3, now: now,
+			query: url.Values{ExpiresParam: {"soon"}, SignatureParam: query[SignatureParam]}},
+	} {
+		if got := tt.signer.Verify(tt.domain, tt.ticket, tt.attachment, tt.query, tt.now); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
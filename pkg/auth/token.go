@@ -0,0 +1,31 @@
+// Package auth contains primitives to issue and verify API credentials.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// tokenPrefix makes API tokens recognizable, e.g. by secret scanners.
+const tokenPrefix = "fdi_"
+
+// GenerateToken returns a new random API token.
+func GenerateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random: %v", err)
+	}
+
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex-encoded SHA-256 hash of the token to be stored and looked up in the database.
+// Tokens are random and long enough, so a fast hash without salt is sufficient.
+func HashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,218 @@
+// Package bundle packs an imported ticket with all its data into a zip archive.
+package bundle
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+	"github.com/kirychukyurii/fd-import/pkg/filestorage"
+	"github.com/kirychukyurii/fd-import/pkg/transcript"
+)
+
+const (
+	rawFile        = "ticket.json"
+	transcriptFile = "transcript.html"
+	attachmentsDir = "attachments"
+)
+
+// Bundle represents a ticket with the data packed into the archive:
+//   - ticket.json: the original ticket JSON;
+//   - transcript.html: the rendered transcript of the ticket conversations;
+//   - attachments/: ticket and conversation attachments under their original names.
+type Bundle struct {
+	log           *wlog.Logger
+	attachmentDir string
+
+	domain *models.Domain
+	ticket *models.Ticket
+	raw    []byte
+
+	// attachments lists unique attachments in the order of appearance,
+	// names maps attachment IDs to unique file names in the archive.
+	attachments []*models.Attachment
+	names       map[int64]string
+}
+
+// Load retrieves the ticket data of the given domain from the database.
+// Attachment files are read from the attachment directory only on Write.
+func Load(ctx context.Context, log *wlog.Logger, dbpool *db.Connection, attachmentDir string, domain *models.Domain,
+	ticketID int64) (*Bundle, error) {
+	ticket, err := dbpool.TicketDetails(ctx, domain.ID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("ticket details: %w", err)
+	}
+
+	raw, err := dbpool.RawTicket(ctx, domain.ID, ticketID, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("raw ticket: %w", err)
+	}
+
+	b := &Bundle{
+		log:           log,
+		attachmentDir: attachmentDir,
+		domain:        domain,
+		ticket:        ticket,
+		raw:           raw,
+		names:         make(map[int64]string),
+	}
+
+	used := make(map[string]bool)
+	b.addAttachments(ticket.Attachments, used)
+	for _, cc := range ticket.Conversations {
+		b.addAttachments(cc.Attachments, used)
+	}
+
+	return b, nil
+}
+
+// Name returns the file name of the archive.
+func (b *Bundle) Name() string {
+	return fmt.Sprintf("ticket-%d.zip", b.ticket.ID)
+}
+
+// Write streams the zip archive into w. Missing attachment files are skipped.
+func (b *Bundle) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	if err := b.writeRaw(zw); err != nil {
+		return err
+	}
+
+	if err := b.writeTranscript(zw); err != nil {
+		return err
+	}
+
+	for _, a := range b.attachments {
+		if err := b.writeAttachment(zw, a); err != nil {
+			return fmt.Errorf("attachment (%d): %v", a.ID, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("close zip: %v", err)
+	}
+
+	return nil
+}
+
+func (b *Bundle) writeRaw(zw *zip.Writer) error {
+	f, err := zw.CreateHeader(b.header(rawFile, zip.Deflate, b.ticket.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("create %s: %v", rawFile, err)
+	}
+
+	if _, err := f.Write(b.raw); err != nil {
+		return fmt.Errorf("write %s: %v", rawFile, err)
+	}
+
+	return nil
+}
+
+func (b *Bundle) writeTranscript(zw *zip.Writer) error {
+	f, err := zw.CreateHeader(b.header(transcriptFile, zip.Deflate, b.ticket.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("create %s: %v", transcriptFile, err)
+	}
+
+	opts := &transcript.Options{
+		AttachmentURL: func(a *models.Attachment) string {
+			if name, ok := b.names[a.ID]; ok {
+				return attachmentsDir + "/" + url.PathEscape(name)
+			}
+
+			return ""
+		},
+	}
+
+	if err := transcript.Render(f, b.ticket, opts); err != nil {
+		return fmt.Errorf("render %s: %v", transcriptFile, err)
+	}
+
+	return nil
+}
+
+func (b *Bundle) writeAttachment(zw *zip.Writer, a *models.Attachment) error {
+	file := filestorage.AttachmentPath(b.attachmentDir, b.domain.Name, b.ticket.ID, a.ID, a.Name)
+	src, err := os.Open(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			b.log.Warn("attachment file not found", wlog.Int64("ticket", b.ticket.ID), wlog.Int64("attachment", a.ID),
+				wlog.String("file", file))
+
+			return nil
+		}
+
+		return fmt.Errorf("open file: %v", err)
+	}
+
+	defer src.Close()
+
+	// attachments are mostly compressed formats already, so they are stored as is
+	f, err := zw.CreateHeader(b.header(path.Join(attachmentsDir, b.names[a.ID]), zip.Store, &a.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("create entry: %v", err)
+	}
+
+	if _, err := io.Copy(f, src); err != nil {
+		return fmt.Errorf("copy file: %v", err)
+	}
+
+	return nil
+}
+
+func (b *Bundle) header(name string, method uint16, modified *time.Time) *zip.FileHeader {
+	h := &zip.FileHeader{
+		Name:   name,
+		Method: method,
+	}
+
+	if modified != nil {
+		h.Modified = *modified
+	}
+
+	return h
+}
+
+// addAttachments registers attachments, which were not seen yet, under unique file names.
+func (b *Bundle) addAttachments(attachments []*models.Attachment, used map[string]bool) {
+	for _, a := range attachments {
+		if _, ok := b.names[a.ID]; ok {
+			continue
+		}
+
+		b.names[a.ID] = uniqueName(a, used)
+		b.attachments = append(b.attachments, a)
+	}
+}
+
+// uniqueName returns the attachment name, which is not used yet, adding a " (N)" suffix
+// before the extension on collisions, e.g. "report (1).pdf".
+func uniqueName(a *models.Attachment, used map[string]bool) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(a.Name)
+	if name == "" || name == "." || name == ".." {
+		name = fmt.Sprintf("%d", a.ID)
+	}
+
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+
+	used[candidate] = true
+
+	return candidate
+}
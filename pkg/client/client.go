@@ -0,0 +1,455 @@
+// Package client implements a Go client of the fd-import API described by /openapi.json.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// Error represents an error response of the API.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Msg        string `json:"message,omitempty"`
+
+	// RetryAfter is the delay requested by the server for 429 responses.
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Msg == "" {
+		return fmt.Sprintf("api: %s", http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("api: %d: %s", e.StatusCode, e.Msg)
+}
+
+// Errors matched by API errors of the corresponding status codes with errors.Is.
+var (
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+// Is reports whether the error has the status code of the target error.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrTooManyRequests:
+		return e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+// TicketList represents a page of the ticket list.
+// Next is the cursor of the following page, it is empty on the last page.
+type TicketList struct {
+	Items []*models.Ticket `json:"items"`
+	Next  string           `json:"next,omitempty"`
+}
+
+// SearchList represents a page of the full-text search results.
+type SearchList struct {
+	Items []*models.SearchResult `json:"items"`
+}
+
+// CategoryList represents solution categories of the domain with their folders.
+type CategoryList struct {
+	Items []*models.SolutionCategory `json:"items"`
+}
+
+// ArticleList represents a page of the solution article list.
+type ArticleList struct {
+	Items []*models.SolutionArticle `json:"items"`
+}
+
+// ArticleSearchList represents a page of the solution article search results.
+type ArticleSearchList struct {
+	Items []*models.ArticleSearchResult `json:"items"`
+}
+
+// Readiness represents results of the readiness checks, failed checks contain the error message.
+type Readiness struct {
+	Database          string `json:"database"`
+	AttachmentStorage string `json:"attachment_storage"`
+}
+
+// TicketsParams represents filters and paging of the ticket list, zero values are not sent.
+type TicketsParams struct {
+	Statuses      []int64
+	Priorities    []int64
+	Sources       []int64
+	RequesterName string
+	Tags          []string
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	Subject       string
+	Asc           bool
+	Limit         uint64
+	Cursor        string
+}
+
+func (p *TicketsParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+
+	setList(q, "status", p.Statuses)
+	setList(q, "priority", p.Priorities)
+	setList(q, "source", p.Sources)
+	if len(p.Tags) > 0 {
+		q.Set("tags", strings.Join(p.Tags, ","))
+	}
+
+	if p.RequesterName != "" {
+		q.Set("requester_name", p.RequesterName)
+	}
+
+	if !p.CreatedFrom.IsZero() {
+		q.Set("created_from", p.CreatedFrom.Format(time.RFC3339))
+	}
+
+	if !p.CreatedTo.IsZero() {
+		q.Set("created_to", p.CreatedTo.Format(time.RFC3339))
+	}
+
+	if p.Subject != "" {
+		q.Set("q", p.Subject)
+	}
+
+	if p.Asc {
+		q.Set("sort", "created_at")
+	}
+
+	if p.Limit > 0 {
+		q.Set("limit", strconv.FormatUint(p.Limit, 10))
+	}
+
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+
+	return q
+}
+
+// ArticlesParams represents filters and paging of the solution article list, zero values are not sent.
+type ArticlesParams struct {
+	CategoryID int64
+	FolderID   int64
+	Status     int64
+	Tags       []string
+	Limit      uint64
+	Offset     uint64
+}
+
+func (p *ArticlesParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+
+	if p.CategoryID != 0 {
+		q.Set("category_id", strconv.FormatInt(p.CategoryID, 10))
+	}
+
+	if p.FolderID != 0 {
+		q.Set("folder_id", strconv.FormatInt(p.FolderID, 10))
+	}
+
+	if p.Status != 0 {
+		q.Set("status", strconv.FormatInt(p.Status, 10))
+	}
+
+	if len(p.Tags) > 0 {
+		q.Set("tags", strings.Join(p.Tags, ","))
+	}
+
+	if p.Limit > 0 {
+		q.Set("limit", strconv.FormatUint(p.Limit, 10))
+	}
+
+	if p.Offset > 0 {
+		q.Set("offset", strconv.FormatUint(p.Offset, 10))
+	}
+
+	return q
+}
+
+// Client is a client of the fd-import API.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// New returns a client of the API at baseURL authenticating with the token.
+// http.DefaultClient is used if httpClient is nil.
+func New(baseURL, token string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// Ready returns results of the readiness checks. Failed checks are reported in the result along with the error.
+func (c *Client) Ready(ctx context.Context) (*Readiness, error) {
+	resp, err := c.do(ctx, "/readyz", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	r := &Readiness{}
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		return nil, fmt.Errorf("decode response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return r, &Error{StatusCode: resp.StatusCode, Msg: "not ready"}
+	}
+
+	return r, nil
+}
+
+// Ping checks the access token.
+func (c *Client) Ping(ctx context.Context) error {
+	var s string
+
+	return c.getJSON(ctx, "/ping", nil, &s)
+}
+
+// Tickets returns a page of ticket summaries.
+func (c *Client) Tickets(ctx context.Context, domainID int64, params *TicketsParams) (*TicketList, error) {
+	list := &TicketList{}
+	if err := c.getJSON(ctx, fmt.Sprintf("/%d/tickets", domainID), params.values(), list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Search returns tickets matching the full-text query ranked by relevance. Zero limit selects the default page size.
+func (c *Client) Search(ctx context.Context, domainID int64, query string, limit, offset uint64) (*SearchList, error) {
+	q := url.Values{"q": {query}}
+	if limit > 0 {
+		q.Set("limit", strconv.FormatUint(limit, 10))
+	}
+
+	if offset > 0 {
+		q.Set("offset", strconv.FormatUint(offset, 10))
+	}
+
+	list := &SearchList{}
+	if err := c.getJSON(ctx, fmt.Sprintf("/%d/search", domainID), q, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Ticket returns the ticket with its conversations and attachments.
+func (c *Client) Ticket(ctx context.Context, domainID, ticketID int64) (*models.Ticket, error) {
+	ticket := &models.Ticket{}
+	if err := c.getJSON(ctx, fmt.Sprintf("/%d/ticket/%d", domainID, ticketID), nil, ticket); err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
+}
+
+// RawTicket returns the original Freshdesk ticket JSON or its value selected by the JSON pointer, if it is not empty.
+func (c *Client) RawTicket(ctx context.Context, domainID, ticketID int64, pointer string) (json.RawMessage, error) {
+	q := url.Values{}
+	if pointer != "" {
+		q.Set("pointer", pointer)
+	}
+
+	var raw json.RawMessage
+	if err := c.getJSON(ctx, fmt.Sprintf("/%d/ticket/%d/raw", domainID, ticketID), q, &raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// Bundle returns the zip archive of the ticket. The caller must close the reader.
+func (c *Client) Bundle(ctx context.Context, domainID, ticketID int64) (io.ReadCloser, error) {
+	return c.stream(ctx, fmt.Sprintf("/%d/ticket/%d/bundle.zip", domainID, ticketID), nil)
+}
+
+// Transcript returns the HTML transcript of the ticket. The caller must close the reader.
+func (c *Client) Transcript(ctx context.Context, domainID, ticketID int64) (io.ReadCloser, error) {
+	return c.stream(ctx, fmt.Sprintf("/%d/ticket/%d/transcript.html", domainID, ticketID), nil)
+}
+
+// Attachment returns the attachment file. The caller must close the reader.
+func (c *Client) Attachment(ctx context.Context, domainID, ticketID, id int64) (io.ReadCloser, error) {
+	return c.stream(ctx, fmt.Sprintf("/%d/ticket/%d/attachments/%d", domainID, ticketID, id), nil)
+}
+
+// SolutionCategories returns solution categories of the domain with their folders.
+func (c *Client) SolutionCategories(ctx context.Context, domainID int64) (*CategoryList, error) {
+	list := &CategoryList{}
+	if err := c.getJSON(ctx, fmt.Sprintf("/%d/solutions/categories", domainID), nil, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// SolutionArticles returns a page of solution article summaries.
+func (c *Client) SolutionArticles(ctx context.Context, domainID int64, params *ArticlesParams) (*ArticleList, error) {
+	list := &ArticleList{}
+	if err := c.getJSON(ctx, fmt.Sprintf("/%d/solutions/articles", domainID), params.values(), list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// SearchArticles returns solution articles matching the full-text query ranked by relevance.
+// Zero limit selects the default page size.
+func (c *Client) SearchArticles(ctx context.Context, domainID int64, query string, limit, offset uint64) (*ArticleSearchList, error) {
+	q := url.Values{"q": {query}}
+	if limit > 0 {
+		q.Set("limit", strconv.FormatUint(limit, 10))
+	}
+
+	if offset > 0 {
+		q.Set("offset", strconv.FormatUint(offset, 10))
+	}
+
+	list := &ArticleSearchList{}
+	if err := c.getJSON(ctx, fmt.Sprintf("/%d/solutions/search", domainID), q, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// SolutionArticle returns the solution article with its body and attachments.
+func (c *Client) SolutionArticle(ctx context.Context, domainID, articleID int64) (*models.SolutionArticle, error) {
+	article := &models.SolutionArticle{}
+	if err := c.getJSON(ctx, fmt.Sprintf("/%d/solutions/articles/%d", domainID, articleID), nil, article); err != nil {
+		return nil, err
+	}
+
+	return article, nil
+}
+
+// ArticleAttachment returns the solution article attachment file. The caller must close the reader.
+func (c *Client) ArticleAttachment(ctx context.Context, domainID, articleID, id int64) (io.ReadCloser, error) {
+	return c.stream(ctx, fmt.Sprintf("/%d/solutions/articles/%d/attachments/%d", domainID, articleID, id), nil)
+}
+
+// Download returns the file of the download URL returned by the API, e.g. a signed attachment URL.
+// The caller must close the reader.
+func (c *Client) Download(ctx context.Context, downloadURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %v", err)
+	}
+
+	return c.stream(ctx, u.EscapedPath(), u.Query())
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v any) error {
+	body, err := c.stream(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %v", err)
+	}
+
+	return nil
+}
+
+// stream sends the request and returns the body of the successful response, or the API error.
+func (c *Client) stream(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		return nil, responseError(resp)
+	}
+
+	return resp.Body, nil
+}
+
+func (c *Client) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %v", err)
+	}
+
+	if c.token != "" && !query.Has("signature") {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// responseError decodes the error of the failed response.
+func responseError(resp *http.Response) error {
+	e := &Error{StatusCode: resp.StatusCode}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(e)
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if sec, err := strconv.Atoi(s); err == nil {
+			e.RetryAfter = time.Duration(sec) * time.Second
+		}
+	}
+
+	return e
+}
+
+func setList(q url.Values, key string, list []int64) {
+	if len(list) == 0 {
+		return
+	}
+
+	s := make([]string, 0, len(list))
+	for _, v := range list {
+		s = append(s, strconv.FormatInt(v, 10))
+	}
+
+	q.Set(key, strings.Join(s, ","))
+}
@@ -0,0 +1,256 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/client"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+	"github.com/kirychukyurii/fd-import/pkg/filestorage"
+	"github.com/kirychukyurii/fd-import/pkg/httpserver"
+)
+
+// testDSNEnv names the environment variable with the DSN of a database migrated with `fd-import migrate`.
+// Tests reading imported data are skipped if it is not set.
+const testDSNEnv = "FD_IMPORT_TEST_DSN"
+
+const testToken = "test-token"
+
+// newServer starts the API server in-process. The dbpool may be nil for tests which don't read data.
+func newServer(t *testing.T, dbpool *db.Connection, configure func(cfg *config.Config)) *httptest.Server {
+	t.Helper()
+	cfg := config.New()
+	cfg.Server.Token = testToken
+	cfg.Server.SigningKey = "test-signing-key"
+	cfg.Server.SignedURLTTL = time.Hour
+	cfg.AttachmentDir = t.TempDir()
+	if configure != nil {
+		configure(cfg)
+	}
+
+	srv := httpserver.New(cfg, newLogger())
+	srv.RegisterHandlers(dbpool)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func newLogger() *wlog.Logger {
+	return wlog.NewLogger(&wlog.LoggerConfiguration{EnableConsole: true, ConsoleLevel: wlog.LevelError})
+}
+
+func TestAuthErrors(t *testing.T) {
+	ts := newServer(t, nil, nil)
+	ctx := context.Background()
+
+	if err := client.New(ts.URL, testToken, nil).Ping(ctx); err != nil {
+		t.Fatalf("ping with the master token: %v", err)
+	}
+
+	for name, token := range map[string]string{"missing": "", "invalid": "wrong-token"} {
+		err := client.New(ts.URL, token, nil).Ping(ctx)
+		if !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("%s token: got %v, want unauthorized", name, err)
+		}
+
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != 401 || apiErr.Msg == "" {
+			t.Errorf("%s token: got %#v, want the API error with status 401 and the message", name, err)
+		}
+	}
+
+	q := url.Values{"expires": {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)}, "signature": {"invalid"}}
+	_, err := client.New(ts.URL, "", nil).Download(ctx, ts.URL+"/1/ticket/2/attachments/3?"+q.Encode())
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("invalid signature: got %v, want forbidden", err)
+	}
+}
+
+func TestTooManyRequests(t *testing.T) {
+	ts := newServer(t, nil, func(cfg *config.Config) {
+		cfg.Server.RateLimit = 0.5
+		cfg.Server.RateBurst = 1
+	})
+
+	c := client.New(ts.URL, testToken, nil)
+	ctx := context.Background()
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+
+	err := c.Ping(ctx)
+	if !errors.Is(err, client.ErrTooManyRequests) {
+		t.Fatalf("second request: got %v, want too many requests", err)
+	}
+
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter < time.Second || apiErr.RetryAfter > 2*time.Second {
+		t.Errorf("got %#v, want Retry-After from 1 to 2 seconds", err)
+	}
+}
+
+// seed imports tickets of a new domain: ticket IDs 1 to n created a minute apart, the latest one has
+// the attachment with the stored file. The domain is deleted when the test completes.
+func seed(t *testing.T, dbpool *db.Connection, attachmentDir string, n int) (int64, []byte) {
+	t.Helper()
+	ctx := context.Background()
+	name := fmt.Sprintf("client-test-%d", time.Now().UnixNano())
+	domain, err := dbpool.CreateDomain(ctx, name)
+	if err != nil {
+		t.Fatalf("create domain: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := dbpool.DeleteDomain(context.Background(), domain, 1000); err != nil {
+			t.Errorf("delete domain: %v", err)
+		}
+	})
+
+	content := []byte("attachment content")
+	created := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		createdAt := created.Add(time.Duration(i) * time.Minute)
+		ticket := &models.Ticket{
+			Raw:      []byte(fmt.Sprintf(`{"id": %d}`, i)),
+			AWSKey:   fmt.Sprintf("/requester/%d.json", i),
+			DomainID: domain, RequesterName: "requester",
+			ID: int64(i), Subject: fmt.Sprintf("Ticket %d", i), Status: 2, Priority: 1, Source: 1,
+			CreatedAt: &createdAt, UpdatedAt: &createdAt,
+		}
+
+		if i == n {
+			ticket.Attachments = []*models.Attachment{{ID: 100, Name: "note.txt", ContentType: "text/plain",
+				FileSize: int64(len(content)), URL: "https://example.com/note.txt", CreatedAt: createdAt, UpdatedAt: createdAt}}
+		}
+
+		if err := dbpool.CreateTicket(ctx, ticket); err != nil {
+			t.Fatalf("create ticket %d: %v", i, err)
+		}
+	}
+
+	file := filestorage.AttachmentPath(attachmentDir, name, int64(n), 100, "note.txt")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(file, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return domain, content
+}
+
+func TestImportedData(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	ctx := context.Background()
+	dbpool, err := db.New(ctx, newLogger(), dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	attachmentDir := t.TempDir()
+	ts := newServer(t, dbpool, func(cfg *config.Config) {
+		cfg.AttachmentDir = attachmentDir
+	})
+
+	domain, content := seed(t, dbpool, attachmentDir, 3)
+	c := client.New(ts.URL, testToken, nil)
+
+	t.Run("pagination", func(t *testing.T) {
+		var ids []int64
+		params := &client.TicketsParams{Limit: 2}
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatal("pagination doesn't stop")
+			}
+
+			list, err := c.Tickets(ctx, domain, params)
+			if err != nil {
+				t.Fatalf("tickets: %v", err)
+			}
+
+			for _, ticket := range list.Items {
+				ids = append(ids, ticket.ID)
+			}
+
+			if list.Next == "" {
+				break
+			}
+
+			params.Cursor = list.Next
+		}
+
+		if fmt.Sprint(ids) != "[3 2 1]" {
+			t.Errorf("got tickets %v, want [3 2 1]", ids)
+		}
+	})
+
+	t.Run("ticket", func(t *testing.T) {
+		ticket, err := c.Ticket(ctx, domain, 3)
+		if err != nil {
+			t.Fatalf("ticket: %v", err)
+		}
+
+		if ticket.ID != 3 || ticket.Subject != "Ticket 3" || ticket.CreatedAt == nil {
+			t.Errorf("got ticket %+v", ticket)
+		}
+
+		if len(ticket.Attachments) != 1 || ticket.Attachments[0].Name != "note.txt" ||
+			ticket.Attachments[0].DownloadURL == "" {
+			t.Fatalf("got attachments %+v, want note.txt with the download URL", ticket.Attachments)
+		}
+
+		if _, err := c.Ticket(ctx, domain, 404); !errors.Is(err, client.ErrNotFound) {
+			t.Errorf("unknown ticket: got %v, want not found", err)
+		}
+	})
+
+	t.Run("attachment", func(t *testing.T) {
+		ticket, err := c.Ticket(ctx, domain, 3)
+		if err != nil {
+			t.Fatalf("ticket: %v", err)
+		}
+
+		for name, open := range map[string]func() (io.ReadCloser, error){
+			"token": func() (io.ReadCloser, error) {
+				return c.Attachment(ctx, domain, 3, 100)
+			},
+			"signed URL": func() (io.ReadCloser, error) {
+				return client.New(ts.URL, "", nil).Download(ctx, ts.URL+ticket.Attachments[0].DownloadURL)
+			},
+		} {
+			rc, err := open()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			b, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatalf("%s: read: %v", name, err)
+			}
+
+			if string(b) != string(content) {
+				t.Errorf("%s: got %q, want %q", name, b, content)
+			}
+		}
+	})
+}
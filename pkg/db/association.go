@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// ticketAssociations derives links of the ticket from its Freshdesk association type and the list
+// of associated tickets: parent and tracker tickets list their child and related tickets, child and related
+// tickets list their parent or tracker ticket. Child and related tickets without the list are linked
+// to the ticket parent_id, if any.
+func ticketAssociations(ticket *models.Ticket) []*models.TicketAssociation {
+	ids := ticket.AssociatedTickets
+	switch ticket.AssociationType {
+	case models.AssociationChild, models.AssociationRelated:
+		if len(ids) == 0 && ticket.ParentID != 0 {
+			ids = []int64{ticket.ParentID}
+		}
+	case models.AssociationParent, models.AssociationTracker:
+	default:
+		return nil
+	}
+
+	associations := make([]*models.TicketAssociation, 0, len(ids))
+	for _, id := range ids {
+		a := &models.TicketAssociation{AWSKey: ticket.AWSKey, DomainID: ticket.DomainID}
+		switch ticket.AssociationType {
+		case models.AssociationParent:
+			a.ParentID, a.ChildID, a.AssociationType = ticket.ID, id, models.AssociationChild
+		case models.AssociationTracker:
+			a.ParentID, a.ChildID, a.AssociationType = ticket.ID, id, models.AssociationRelated
+		default:
+			a.ParentID, a.ChildID, a.AssociationType = id, ticket.ID, ticket.AssociationType
+		}
+
+		associations = append(associations, a)
+	}
+
+	return associations
+}
+
+// parentID returns the parent or tracker ticket ID of the ticket from its links, or 0.
+func parentID(ticket *models.Ticket, associations []*models.TicketAssociation) int64 {
+	for _, a := range associations {
+		if a.ChildID == ticket.ID {
+			return a.ParentID
+		}
+	}
+
+	return 0
+}
+
+// createAssociations inserts links of the ticket into the fresh.ticket_association table within transaction,
+// updating existing ones, and sets parent_id of the linked child and related tickets and their conversations.
+// Links are imported from both sides, so parent_id is set regardless of the order the tickets are imported.
+func (c *ConnectionTx) createAssociations(ctx context.Context, ticket *models.Ticket, associations []*models.TicketAssociation) error {
+	columns := []string{"aws_key", "domain_id", "parent_id", "child_id", "association_type"}
+	suffix := upsertSuffix([]string{"domain_id", "parent_id", "child_id"}, columns)
+	for _, a := range associations {
+		sql, args, err := c.conn.psql.Insert("fresh.ticket_association").Columns(columns...).
+			Values(a.AWSKey, a.DomainID, a.ParentID, a.ChildID, a.AssociationType).Suffix(suffix).ToSql()
+		if err != nil {
+			return fmt.Errorf("[%d-%d] build query: %v", a.ParentID, a.ChildID, err)
+		}
+
+		if _, err := c.tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("[%d-%d] exec query: %v", a.ParentID, a.ChildID, err)
+		}
+	}
+
+	for _, q := range []string{syncTicketParentQuery, syncConversationParentQuery} {
+		if _, err := c.tx.Exec(ctx, q, ticket.DomainID, ticket.ID); err != nil {
+			return fmt.Errorf("sync parent_id: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// syncTicketParentQuery sets parent_id of tickets linked to the given one as child or related tickets.
+const syncTicketParentQuery = `
+UPDATE fresh.ticket t
+SET parent_id = a.parent_id
+FROM fresh.ticket_association a
+WHERE a.domain_id = $1
+  AND (a.parent_id = $2 OR a.child_id = $2)
+  AND t.domain_id = a.domain_id
+  AND t.id = a.child_id
+  AND t.parent_id IS DISTINCT FROM a.parent_id`
+
+// syncConversationParentQuery sets parent_id of conversations of tickets linked to the given one
+// as child or related tickets.
+const syncConversationParentQuery = `
+UPDATE fresh.conversation c
+SET parent_id = a.parent_id
+FROM fresh.ticket_association a
+WHERE a.domain_id = $1
+  AND (a.parent_id = $2 OR a.child_id = $2)
+  AND c.domain_id = a.domain_id
+  AND c.ticket_id = a.child_id
+  AND c.parent_id IS DISTINCT FROM a.parent_id`
+
+// linkedTicketsQuery selects tickets linked to the given one with the latest imported subject and status.
+const linkedTicketsQuery = `
+SELECT a.parent_id, a.child_id, coalesce(a.association_type, 0), t.id IS NOT NULL, coalesce(t.subject, ''),
+       coalesce(t.status, 0), coalesce((SELECT s.name FROM fresh.ticket_status s WHERE s.value = t.status LIMIT 1), '')
+FROM fresh.ticket_association a
+         LEFT JOIN LATERAL (SELECT id, subject, status
+                            FROM fresh.ticket
+                            WHERE domain_id = a.domain_id
+                              AND id = CASE WHEN a.parent_id = $2 THEN a.child_id ELSE a.parent_id END
+                            ORDER BY row_id DESC
+                            LIMIT 1) t ON true
+WHERE a.domain_id = $1
+  AND (a.parent_id = $2 OR a.child_id = $2)
+ORDER BY a.child_id = $2 DESC, a.parent_id, a.child_id`
+
+// LinkedTickets retrieves tickets linked to the given one: its parent or tracker ticket first,
+// then its child or related tickets. Linked tickets which are not imported have only the ID and relation.
+func (c *Connection) LinkedTickets(ctx context.Context, domain, id int64) ([]*models.LinkedTicket, error) {
+	rows, err := c.pool.Query(ctx, linkedTicketsQuery, domain, id)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	tickets := make([]*models.LinkedTicket, 0)
+	for rows.Next() {
+		var (
+			parent, child, typ int64
+			t                  models.LinkedTicket
+		)
+
+		if err := rows.Scan(&parent, &child, &typ, &t.Imported, &t.Subject, &t.Status, &t.StatusName); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		t.ID, t.Relation = linkedRelation(id, parent, child, typ)
+		tickets = append(tickets, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return tickets, nil
+}
+
+// linkedRelation returns the ID and the relation of the ticket linked to the given one.
+func linkedRelation(id, parent, child, typ int64) (int64, string) {
+	switch {
+	case parent == id && typ == models.AssociationRelated:
+		return child, "related"
+	case parent == id:
+		return child, "child"
+	case typ == models.AssociationRelated:
+		return parent, "tracker"
+	default:
+		return parent, "parent"
+	}
+}
@@ -9,17 +9,78 @@ import (
 	"github.com/kirychukyurii/fd-import/models"
 )
 
+// Attachment retrieves the attachment of the given domain by ID. If the attachment was imported
+// several times, the latest imported row is returned.
 func (c *Connection) Attachment(ctx context.Context, domain, id int64) (*models.Attachment, error) {
-	sql, args, err := c.psql.Select("id", "name", "content_type", "file_size").From("fresh.attachment").
-		Where(sq.Eq{"id": id, "domain_id": domain}).ToSql()
+	sql, args, err := c.psql.Select("id", "name", "content_type", "file_size", "coalesce(checksum, '')").
+		From("fresh.attachment").
+		Where(sq.Eq{"id": id, "domain_id": domain}).
+		OrderBy("row_id DESC").
+		Limit(1).ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build query: %w", err)
 	}
 
 	var attachment models.Attachment
-	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&attachment.ID, &attachment.Name, &attachment.ContentType, &attachment.FileSize); err != nil {
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&attachment.ID, &attachment.Name, &attachment.ContentType, &attachment.FileSize,
+		&attachment.Checksum); err != nil {
 		return nil, fmt.Errorf("query row: %w", err)
 	}
 
 	return &attachment, nil
 }
+
+// Attachments retrieves attachments of the given domain by IDs. If an attachment was imported
+// several times, the latest imported row is returned.
+func (c *Connection) Attachments(ctx context.Context, domain int64, ids []int64) ([]*models.Attachment, error) {
+	if len(ids) == 0 {
+		return []*models.Attachment{}, nil
+	}
+
+	sql, args, err := c.psql.Select("DISTINCT ON (id) id", "name", "content_type", "file_size", "url",
+		"coalesce(thumb_url, '')", "created_at", "updated_at", "imported_at", "domain_id", "coalesce(checksum, '')").
+		From("fresh.attachment").
+		Where(sq.Eq{"domain_id": domain, "id": ids}).
+		OrderBy("id", "row_id DESC").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	attachments := make([]*models.Attachment, 0, len(ids))
+	for rows.Next() {
+		var a models.Attachment
+		if err := rows.Scan(&a.ID, &a.Name, &a.ContentType, &a.FileSize, &a.URL, &a.ThumbURL, &a.CreatedAt,
+			&a.UpdatedAt, &a.ImportedAt, &a.DomainID, &a.Checksum); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		attachments = append(attachments, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// SetAttachmentChecksum stores the checksum of the downloaded attachment file.
+func (c *Connection) SetAttachmentChecksum(ctx context.Context, domain, id int64, checksum string) error {
+	sql, args, err := c.psql.Update("fresh.attachment").Set("checksum", checksum).
+		Where(sq.Eq{"id": id, "domain_id": domain}).ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	if _, err := c.pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("exec query: %w", err)
+	}
+
+	return nil
+}
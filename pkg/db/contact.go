@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// contactColumns is the list of fresh.contact columns set on import, in the order of contactValues.
+var contactColumns = []string{"aws_key", "domain_id", "id", "name", "email", "other_emails", "phone", "mobile",
+	"twitter_id", "unique_external_id", "company_id", "other_companies", "job_title", "description", "address",
+	"language", "time_zone", "active", "deleted", "view_all_tickets", "tags", "custom_fields", "created_at", "updated_at"}
+
+func contactValues(c *models.Contact) []any {
+	return []any{c.AWSKey, c.DomainID, c.ID, c.Name, c.Email, c.OtherEmails, c.Phone, c.Mobile, c.TwitterID,
+		c.UniqueExternalID, c.CompanyID, c.OtherCompanies, c.JobTitle, c.Description, c.Address, c.Language,
+		c.TimeZone, c.Active, c.Deleted, c.ViewAllTickets, c.Tags, c.CustomFields, c.CreatedAt, c.UpdatedAt}
+}
+
+// CreateContacts inserts contacts into the fresh.contact table within transaction.
+// Contacts which already exist in the domain are updated, so exports can be re-imported.
+func (c *Connection) CreateContacts(ctx context.Context, contacts []*models.Contact) error {
+	rows := make([]referenceRow, 0, len(contacts))
+	for _, contact := range contacts {
+		rows = append(rows, referenceRow{id: contact.ID, values: contactValues(contact)})
+	}
+
+	return c.upsertReferences(ctx, "fresh.contact", contactColumns, rows)
+}
+
+// Contact retrieves the contact by the domain ID and contact ID.
+func (c *Connection) Contact(ctx context.Context, domain, id int64) (*models.Contact, error) {
+	sql, args, err := c.psql.Select("row_id", "imported_at", "coalesce(aws_key, '')", "domain_id", "id",
+		"coalesce(name, '')", "coalesce(email, '')", "other_emails", "coalesce(phone, '')", "coalesce(mobile, '')",
+		"coalesce(twitter_id, '')", "coalesce(unique_external_id, '')", "coalesce(company_id, 0)", "other_companies",
+		"coalesce(job_title, '')", "coalesce(description, '')", "coalesce(address, '')", "coalesce(language, '')",
+		"coalesce(time_zone, '')", "coalesce(active, false)", "coalesce(deleted, false)",
+		"coalesce(view_all_tickets, false)", "tags", "custom_fields", "created_at", "updated_at").
+		From("fresh.contact").
+		Where(sq.Eq{"domain_id": domain, "id": id}).
+		Limit(1).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	var ct models.Contact
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&ct.RowID, &ct.ImportedAt, &ct.AWSKey, &ct.DomainID, &ct.ID,
+		&ct.Name, &ct.Email, &ct.OtherEmails, &ct.Phone, &ct.Mobile, &ct.TwitterID, &ct.UniqueExternalID,
+		&ct.CompanyID, &ct.OtherCompanies, &ct.JobTitle, &ct.Description, &ct.Address, &ct.Language, &ct.TimeZone,
+		&ct.Active, &ct.Deleted, &ct.ViewAllTickets, &ct.Tags, &ct.CustomFields, &ct.CreatedAt, &ct.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	return &ct, nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// Conversations retrieves conversations of the given ticket from the `fresh.conversation` table
+// ordered by creation time. Attachments are not loaded, only their IDs. The user name is resolved
+// from the imported agents, or contacts for messages of the requester.
+func (c *Connection) Conversations(ctx context.Context, domain, ticket int64) ([]*models.Conversation, error) {
+	sql, args, err := c.psql.Select("row_id", "imported_at", "id", "ticket_id", "coalesce(parent_id, 0)", "coalesce(body, '')",
+		"coalesce(body_text, '')", "coalesce(incoming, false)", "to_emails", "coalesce(category, 0)",
+		"coalesce(from_email, '')", "cc_emails", "bcc_emails", "coalesce(private, false)", "coalesce(source, 0)",
+		"coalesce(source_additional_info, '')", "coalesce(support_email, '')", "cloud_files",
+		"coalesce(association_type, 0)", "coalesce(email_failure_count, 0)", "coalesce(thread_id, 0)",
+		"coalesce(thread_message_id, 0)", "coalesce(auto_response, false)", "coalesce(automation_id, 0)",
+		"coalesce(automation_type_id, 0)", "outgoing_failures", "coalesce(user_id, 0)", "last_edited_at",
+		"coalesce(last_edited_user_id, 0)", "created_at", "updated_at", "attachment_ids",
+		"coalesce((SELECT ag.name FROM fresh.agent ag WHERE ag.domain_id = conversation.domain_id AND ag.id = conversation.user_id LIMIT 1), "+
+			"(SELECT ct.name FROM fresh.contact ct WHERE ct.domain_id = conversation.domain_id AND ct.id = conversation.user_id LIMIT 1), '')").
+		From("fresh.conversation").
+		Where(sq.Eq{"domain_id": domain, "ticket_id": ticket}).
+		OrderBy("created_at", "id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	conversations := make([]*models.Conversation, 0)
+	for rows.Next() {
+		var cc models.Conversation
+		if err := rows.Scan(&cc.RowID, &cc.ImportedAt, &cc.Id, &cc.TicketID, &cc.ParentID, &cc.Body, &cc.BodyText, &cc.Incoming,
+			&cc.ToEmails, &cc.Category, &cc.FromEmail, &cc.CCEmails, &cc.BCCEmails, &cc.Private, &cc.Source,
+			&cc.SourceAdditionalInfo, &cc.SupportEmail, &cc.CloudFiles, &cc.AssociationType, &cc.EmailFailureCount,
+			&cc.ThreadID, &cc.ThreadMessageID, &cc.AutoResponse, &cc.AutomationID, &cc.AutomationTypeID,
+			&cc.OutgoingFailures, &cc.UserID, &cc.LastEditedAt, &cc.LastEditedUserID, &cc.CreatedAt, &cc.UpdatedAt,
+			&cc.AttachmentIDs, &cc.UserName); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		conversations = append(conversations, &cc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return conversations, nil
+}
@@ -52,6 +52,11 @@ func New(ctx context.Context, log *wlog.Logger, dsn string) (*Connection, error)
 	}, nil
 }
 
+// Ping checks that the database is reachable.
+func (c *Connection) Ping(ctx context.Context) error {
+	return c.pool.Ping(ctx)
+}
+
 func (c *Connection) STDLib() *sql.DB {
 	return stdlib.OpenDBFromPool(c.pool)
 }
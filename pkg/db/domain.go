@@ -5,12 +5,13 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/webitel/wlog"
 
 	"github.com/kirychukyurii/fd-import/models"
 )
 
 func (c *Connection) Domain(ctx context.Context, d *models.Domain) (*models.Domain, error) {
-	query := c.psql.Select("id", "name").From("fresh.domain")
+	query := c.psql.Select("id", "name", "language::text").From("fresh.domain")
 	if d.ID != 0 {
 		query = query.Where(sq.Eq{"id": d.ID})
 	}
@@ -25,13 +26,43 @@ func (c *Connection) Domain(ctx context.Context, d *models.Domain) (*models.Doma
 	}
 
 	var domain models.Domain
-	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&domain.ID, &domain.Name); err != nil {
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&domain.ID, &domain.Name, &domain.Language); err != nil {
 		return nil, fmt.Errorf("query row: %w", err)
 	}
 
 	return &domain, nil
 }
 
+// Domains returns all domains ordered by ID.
+func (c *Connection) Domains(ctx context.Context) ([]*models.Domain, error) {
+	sql, args, err := c.psql.Select("id", "name", "language::text").From("fresh.domain").OrderBy("id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	domains := make([]*models.Domain, 0)
+	for rows.Next() {
+		var domain models.Domain
+		if err := rows.Scan(&domain.ID, &domain.Name, &domain.Language); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		domains = append(domains, &domain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return domains, nil
+}
+
 func (c *Connection) CreateDomain(ctx context.Context, name string) (int64, error) {
 	sql, args, err := c.psql.Insert("fresh.domain").SetMap(map[string]interface{}{"name": name}).
 		Suffix("RETURNING id").ToSql()
@@ -46,3 +77,200 @@ func (c *Connection) CreateDomain(ctx context.Context, name string) (int64, erro
 
 	return id, nil
 }
+
+// RenameDomain sets a new name for the given domain ID.
+func (c *Connection) RenameDomain(ctx context.Context, domain int64, name string) error {
+	sql, args, err := c.psql.Update("fresh.domain").Set("name", name).Where(sq.Eq{"id": domain}).ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %v", err)
+	}
+
+	tag, err := c.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("exec query: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrDBNoExists
+	}
+
+	return nil
+}
+
+// SetDomainLanguage sets the text search configuration of the given domain ID, e.g. english or simple,
+// and rebuilds search vectors of the domain tickets, conversations and solution articles.
+func (c *Connection) SetDomainLanguage(ctx context.Context, domain int64, language string) error {
+	fn := func(ctx context.Context, tx *ConnectionTx) error {
+		sql, args, err := c.psql.Update("fresh.domain").Set("language", sq.Expr("?::regconfig", language)).
+			Where(sq.Eq{"id": domain}).ToSql()
+		if err != nil {
+			return fmt.Errorf("build query: %v", err)
+		}
+
+		tag, err := tx.tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("exec query: %v", err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			return ErrDBNoExists
+		}
+
+		// search vectors are computed by triggers on update
+		for _, table := range []string{"fresh.ticket", "fresh.conversation", "fresh.solution_article"} {
+			sql, args, err := c.psql.Update(table).Set("search_vector", nil).Where(sq.Eq{"domain_id": domain}).ToSql()
+			if err != nil {
+				return fmt.Errorf("build query: %v", err)
+			}
+
+			if _, err := tx.tx.Exec(ctx, sql, args...); err != nil {
+				return fmt.Errorf("rebuild %s search vectors: %v", table, err)
+			}
+		}
+
+		return nil
+	}
+
+	return c.WithTx(ctx, fn)
+}
+
+// domainTables is the list of tables, which rows are bound to the domain by the domain_id column.
+// The order matters for DeleteDomain: dependent rows go first.
+var domainTables = []string{"fresh.conversation", "fresh.attachment", "fresh.ticket", "fresh.ticket_raw", "fresh.contact",
+	"fresh.company", "fresh.agent", "fresh.agent_group", "fresh.ticket_field", "fresh.ticket_association",
+	"fresh.time_entry", "fresh.satisfaction_rating", "fresh.solution_article", "fresh.solution_folder",
+	"fresh.solution_category"}
+
+// DomainStats summarizes data imported for the given domain ID: row counts,
+// total size of attachments and the time of the last imported ticket.
+func (c *Connection) DomainStats(ctx context.Context, domain int64) (*models.DomainStats, error) {
+	sql, args, err := c.psql.Select().
+		Column(sq.Expr("(SELECT count(*) FROM fresh.ticket WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT count(*) FROM fresh.ticket_raw WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT count(*) FROM fresh.conversation WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT count(*) FROM fresh.attachment WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT coalesce(sum(file_size), 0) FROM fresh.attachment WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT count(*) FROM fresh.contact WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT count(*) FROM fresh.company WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT count(*) FROM fresh.agent WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT count(*) FROM fresh.agent_group WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT count(*) FROM fresh.solution_article WHERE domain_id = ?)", domain)).
+		Column(sq.Expr("(SELECT max(imported_at) FROM fresh.ticket_raw WHERE domain_id = ?)", domain)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	var stats models.DomainStats
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&stats.Tickets, &stats.RawTickets, &stats.Conversations, &stats.Attachments,
+		&stats.AttachmentBytes, &stats.Contacts, &stats.Companies, &stats.Agents, &stats.Groups, &stats.Articles,
+		&stats.LastImportedAt); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// DeleteDomain removes all imported rows of the given domain ID and the domain itself.
+// Rows are deleted in batches of the given size, each batch in its own statement,
+// so the locks are held only for a short time. The domain itself is deleted only if no rows
+// of the domain are left, e.g. inserted by a concurrent import, so they can be deleted by a retry.
+func (c *Connection) DeleteDomain(ctx context.Context, domain int64, batch uint64) error {
+	if batch < 1 {
+		return fmt.Errorf("batch size must be at least 1")
+	}
+
+	for _, table := range domainTables {
+		deleted, err := c.deleteDomainRows(ctx, table, domain, batch)
+		if err != nil {
+			return fmt.Errorf("delete from %s: %v", table, err)
+		}
+
+		c.log.Info("deleted domain rows", wlog.String("table", table), wlog.Int64("domain", domain), wlog.Int64("rows", deleted))
+	}
+
+	for _, table := range domainTables {
+		n, err := c.domainRows(ctx, table, domain)
+		if err != nil {
+			return fmt.Errorf("count %s: %v", table, err)
+		}
+
+		if n > 0 {
+			return fmt.Errorf("%d rows of the domain are left in %s", n, table)
+		}
+	}
+
+	sql, args, err := c.psql.Delete("fresh.domain").Where(sq.Eq{"id": domain}).ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %v", err)
+	}
+
+	if _, err := c.pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("exec query: %v", err)
+	}
+
+	return nil
+}
+
+// TableRows is the number of rows of the domain in the table.
+type TableRows struct {
+	Table string
+	Rows  int64
+}
+
+// DomainRows returns the number of rows of the given domain ID in every table bound to the domain,
+// in the order DeleteDomain deletes them.
+func (c *Connection) DomainRows(ctx context.Context, domain int64) ([]*TableRows, error) {
+	rows := make([]*TableRows, 0, len(domainTables))
+	for _, table := range domainTables {
+		n, err := c.domainRows(ctx, table, domain)
+		if err != nil {
+			return nil, fmt.Errorf("count %s: %v", table, err)
+		}
+
+		rows = append(rows, &TableRows{Table: table, Rows: n})
+	}
+
+	return rows, nil
+}
+
+// domainRows returns the number of rows of the given domain ID in the table.
+func (c *Connection) domainRows(ctx context.Context, table string, domain int64) (int64, error) {
+	sql, args, err := c.psql.Select("count(*)").From(table).Where(sq.Eq{"domain_id": domain}).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build query: %v", err)
+	}
+
+	var n int64
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return n, nil
+}
+
+// deleteDomainRows deletes rows of the given domain ID from the table by batches until nothing is left.
+// It returns the total number of deleted rows.
+func (c *Connection) deleteDomainRows(ctx context.Context, table string, domain int64, batch uint64) (int64, error) {
+	sql, args, err := c.psql.Delete(table).
+		Where(sq.Expr(fmt.Sprintf("ctid IN (SELECT ctid FROM %s WHERE domain_id = ? LIMIT ?)", table), domain, batch)).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build query: %v", err)
+	}
+
+	var total int64
+	for {
+		tag, err := c.pool.Exec(ctx, sql, args...)
+		if err != nil {
+			return total, fmt.Errorf("exec query: %v", err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			return total, nil
+		}
+
+		total += tag.RowsAffected()
+		c.log.Debug("deleted batch", wlog.String("table", table), wlog.Int64("rows", tag.RowsAffected()))
+	}
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// referenceRow is a row of the table with records exported by Freshdesk IDs: reference data
+// (contacts, companies, agents, groups, ticket fields) or records linked to tickets (time entries, ratings).
+type referenceRow struct {
+	id     int64
+	values []any
+}
+
+// upsertReferences inserts rows into the table within transaction. The table must have
+// a unique index on (domain_id, id): rows which already exist in the domain are updated,
+// so exports can be re-imported.
+func (c *Connection) upsertReferences(ctx context.Context, table string, columns []string, rows []referenceRow) error {
+	suffix := upsertSuffix([]string{"domain_id", "id"}, columns)
+	fn := func(ctx context.Context, tx *ConnectionTx) error {
+		for _, row := range rows {
+			sql, args, err := tx.conn.psql.Insert(table).Columns(columns...).Values(row.values...).Suffix(suffix).ToSql()
+			if err != nil {
+				return fmt.Errorf("[%d] build query: %v", row.id, err)
+			}
+
+			if _, err := tx.tx.Exec(ctx, sql, args...); err != nil {
+				return fmt.Errorf("[%d] exec query: %v", row.id, err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := c.WithTx(ctx, fn); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upsertSuffix returns the ON CONFLICT clause updating the given columns, except the conflict target ones,
+// with the values of the inserted row. The imported_at column is refreshed too.
+func upsertSuffix(target, columns []string) string {
+	set := make([]string, 0, len(columns)+1)
+	for _, col := range columns {
+		if !slices.Contains(target, col) {
+			set = append(set, fmt.Sprintf("%s = excluded.%s", col, col))
+		}
+	}
+
+	set = append(set, "imported_at = now()")
+
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(target, ", "), strings.Join(set, ", "))
+}
+
+// CreateCompanies inserts companies into the fresh.company table, updating existing ones.
+func (c *Connection) CreateCompanies(ctx context.Context, companies []*models.Company) error {
+	columns := []string{"aws_key", "domain_id", "id", "name", "description", "note", "domains", "health_score",
+		"account_tier", "renewal_date", "industry", "custom_fields", "created_at", "updated_at"}
+	rows := make([]referenceRow, 0, len(companies))
+	for _, co := range companies {
+		rows = append(rows, referenceRow{id: co.ID, values: []any{co.AWSKey, co.DomainID, co.ID, co.Name,
+			co.Description, co.Note, co.Domains, co.HealthScore, co.AccountTier, co.RenewalDate, co.Industry,
+			co.CustomFields, co.CreatedAt, co.UpdatedAt}})
+	}
+
+	return c.upsertReferences(ctx, "fresh.company", columns, rows)
+}
+
+// CreateAgents inserts agents into the fresh.agent table, updating existing ones.
+// Contact details of the agent are stored in the agent row.
+func (c *Connection) CreateAgents(ctx context.Context, agents []*models.Agent) error {
+	columns := []string{"aws_key", "domain_id", "id", "name", "email", "phone", "mobile", "job_title", "language",
+		"time_zone", "active", "available", "occasional", "ticket_scope", "type", "group_ids", "role_ids", "signature",
+		"last_active_at", "created_at", "updated_at"}
+	rows := make([]referenceRow, 0, len(agents))
+	for _, a := range agents {
+		ct := a.Contact
+		if ct == nil {
+			ct = &models.AgentContact{}
+		}
+
+		rows = append(rows, referenceRow{id: a.ID, values: []any{a.AWSKey, a.DomainID, a.ID, ct.Name, ct.Email,
+			ct.Phone, ct.Mobile, ct.JobTitle, ct.Language, ct.TimeZone, ct.Active, a.Available, a.Occasional,
+			a.TicketScope, a.Type, a.GroupIDs, a.RoleIDs, a.Signature, a.LastActiveAt, a.CreatedAt, a.UpdatedAt}})
+	}
+
+	return c.upsertReferences(ctx, "fresh.agent", columns, rows)
+}
+
+// CreateGroups inserts groups into the fresh.agent_group table, updating existing ones.
+func (c *Connection) CreateGroups(ctx context.Context, groups []*models.Group) error {
+	columns := []string{"aws_key", "domain_id", "id", "name", "description", "escalate_to", "unassigned_for",
+		"business_hour_id", "group_type", "agent_ids", "auto_ticket_assign", "created_at", "updated_at"}
+	rows := make([]referenceRow, 0, len(groups))
+	for _, g := range groups {
+		rows = append(rows, referenceRow{id: g.ID, values: []any{g.AWSKey, g.DomainID, g.ID, g.Name, g.Description,
+			g.EscalateTo, g.UnassignedFor, g.BusinessHourID, g.GroupType, g.AgentIDs, g.AutoTicketAssign,
+			g.CreatedAt, g.UpdatedAt}})
+	}
+
+	return c.upsertReferences(ctx, "fresh.agent_group", columns, rows)
+}
+
+// CreateTicketFields inserts ticket field definitions into the fresh.ticket_field table, updating existing ones.
+func (c *Connection) CreateTicketFields(ctx context.Context, fields []*models.TicketField) error {
+	columns := []string{"aws_key", "domain_id", "id", "name", "label", "label_for_customers", "description", "type",
+		"position", `"default"`, "required_for_agents", "choices", "nested_ticket_fields", "created_at", "updated_at"}
+	rows := make([]referenceRow, 0, len(fields))
+	for _, f := range fields {
+		rows = append(rows, referenceRow{id: f.ID, values: []any{f.AWSKey, f.DomainID, f.ID, f.Name, f.Label,
+			f.LabelForCustomers, f.Description, f.Type, f.Position, f.Default, f.RequiredForAgents, f.Choices,
+			f.NestedTicketFields, f.CreatedAt, f.UpdatedAt}})
+	}
+
+	return c.upsertReferences(ctx, "fresh.ticket_field", columns, rows)
+}
+
+// TicketFields retrieves ticket field definitions of the domain ordered by position.
+func (c *Connection) TicketFields(ctx context.Context, domain int64) ([]*models.TicketField, error) {
+	sql, args, err := c.psql.Select("row_id", "imported_at", "coalesce(aws_key, '')", "domain_id", "id",
+		"coalesce(name, '')", "coalesce(label, '')", "coalesce(label_for_customers, '')", "coalesce(description, '')",
+		"coalesce(type, '')", "coalesce(position, 0)", `coalesce("default", false)`, "coalesce(required_for_agents, false)",
+		"choices", "nested_ticket_fields", "created_at", "updated_at").
+		From("fresh.ticket_field").
+		Where(sq.Eq{"domain_id": domain}).
+		OrderBy("position", "id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	fields := make([]*models.TicketField, 0)
+	for rows.Next() {
+		var f models.TicketField
+		if err := rows.Scan(&f.RowID, &f.ImportedAt, &f.AWSKey, &f.DomainID, &f.ID, &f.Name, &f.Label,
+			&f.LabelForCustomers, &f.Description, &f.Type, &f.Position, &f.Default, &f.RequiredForAgents, &f.Choices,
+			&f.NestedTicketFields, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		fields = append(fields, &f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// CreateSatisfactionRatings inserts satisfaction ratings into the fresh.satisfaction_rating table, updating existing ones.
+func (c *Connection) CreateSatisfactionRatings(ctx context.Context, ratings []*models.SatisfactionRating) error {
+	columns := []string{"aws_key", "domain_id", "id", "ticket_id", "survey_id", "user_id", "agent_id", "group_id",
+		"feedback", "ratings", "created_at", "updated_at"}
+	rows := make([]referenceRow, 0, len(ratings))
+	for _, r := range ratings {
+		rows = append(rows, referenceRow{id: r.ID, values: []any{r.AWSKey, r.DomainID, r.ID, r.TicketID, r.SurveyID,
+			r.UserID, r.AgentID, r.GroupID, r.Feedback, r.Ratings, r.CreatedAt, r.UpdatedAt}})
+	}
+
+	return c.upsertReferences(ctx, "fresh.satisfaction_rating", columns, rows)
+}
+
+// SatisfactionRatings retrieves satisfaction ratings of the given ticket ordered by creation time, with names of the agents.
+func (c *Connection) SatisfactionRatings(ctx context.Context, domain, ticket int64) ([]*models.SatisfactionRating, error) {
+	sql, args, err := c.psql.Select("row_id", "imported_at", "coalesce(aws_key, '')", "domain_id", "id",
+		"ticket_id", "coalesce(survey_id, 0)", "coalesce(user_id, 0)", "coalesce(agent_id, 0)",
+		"coalesce(group_id, 0)", "coalesce(feedback, '')", "ratings", "created_at", "updated_at",
+		"coalesce((SELECT ag.name FROM fresh.agent ag WHERE ag.domain_id = satisfaction_rating.domain_id AND ag.id = satisfaction_rating.agent_id LIMIT 1), '')").
+		From("fresh.satisfaction_rating").
+		Where(sq.Eq{"domain_id": domain, "ticket_id": ticket}).
+		OrderBy("created_at", "id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	ratings := make([]*models.SatisfactionRating, 0)
+	for rows.Next() {
+		var r models.SatisfactionRating
+		if err := rows.Scan(&r.RowID, &r.ImportedAt, &r.AWSKey, &r.DomainID, &r.ID, &r.TicketID, &r.SurveyID,
+			&r.UserID, &r.AgentID, &r.GroupID, &r.Feedback, &r.Ratings, &r.CreatedAt, &r.UpdatedAt,
+			&r.AgentName); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		ratings = append(ratings, &r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return ratings, nil
+}
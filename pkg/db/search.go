@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// searchQuery ranks tickets matched by the subject, description or any of conversation bodies.
+// Conversation matches weigh less than matches of the ticket itself. The query uses the text search
+// configuration of the domain and `websearch_to_tsquery` syntax: quoted phrases, OR and -exclusions.
+const searchQuery = `
+WITH q AS (SELECT websearch_to_tsquery(fresh.domain_language($1), $2) query, fresh.domain_language($1) cfg),
+     matches AS (SELECT t.id, ts_rank(t.search_vector, q.query) rank
+                 FROM fresh.ticket t, q
+                 WHERE t.domain_id = $1 AND t.search_vector @@ q.query
+                 UNION ALL
+                 SELECT c.ticket_id, ts_rank(c.search_vector, q.query) * 0.5
+                 FROM fresh.conversation c, q
+                 WHERE c.domain_id = $1 AND c.search_vector @@ q.query),
+     ranked AS (SELECT id, max(rank)::real rank
+                FROM matches
+                GROUP BY id
+                ORDER BY rank DESC, id DESC
+                LIMIT $3 OFFSET $4)
+SELECT t.id, coalesce(t.subject, ''), coalesce(t.status, 0), coalesce(t.requester_name, ''), t.created_at, r.rank,
+       ts_headline(q.cfg, fresh.html_escape(coalesce(t.subject, '')), q.query, $5),
+       ts_headline(q.cfg, fresh.html_escape(coalesce(t.description_text, '')), q.query, $5),
+       coalesce((SELECT ts_headline(q.cfg, fresh.html_escape(c.body_text), q.query, $5)
+                 FROM fresh.conversation c
+                 WHERE c.domain_id = $1 AND c.ticket_id = t.id AND c.search_vector @@ q.query
+                 ORDER BY ts_rank(c.search_vector, q.query) DESC
+                 LIMIT 1), '')
+FROM ranked r
+         CROSS JOIN q
+         JOIN LATERAL (SELECT *
+                       FROM fresh.ticket
+                       WHERE domain_id = $1 AND id = r.id
+                       ORDER BY row_id DESC
+                       LIMIT 1) t ON true
+ORDER BY r.rank DESC, r.id DESC`
+
+// headlineOptions configures `ts_headline` fragments. The text is HTML-escaped before highlighting,
+// so the <mark> tags are the only markup of the fragments.
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+
+// Search performs the full-text search over ticket subjects, descriptions and conversation bodies
+// of the given domain and returns tickets ordered by rank with highlighted fragments.
+func (c *Connection) Search(ctx context.Context, domain int64, q string, limit, offset uint64) ([]*models.SearchResult, error) {
+	rows, err := c.pool.Query(ctx, searchQuery, domain, q, limit, offset, headlineOptions)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	results := make([]*models.SearchResult, 0)
+	for rows.Next() {
+		var r models.SearchResult
+		if err := rows.Scan(&r.TicketID, &r.Subject, &r.Status, &r.RequesterName, &r.CreatedAt, &r.Rank,
+			&r.SubjectHighlight, &r.DescriptionHighlight, &r.ConversationHighlight); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		results = append(results, &r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return results, nil
+}
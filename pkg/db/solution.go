@@ -0,0 +1,348 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// articleColumns are the columns of the fresh.solution_article table selected into models.SolutionArticle,
+// without the article body.
+var articleColumns = []string{"row_id", "imported_at", "coalesce(aws_key, '')", "domain_id", "id",
+	"coalesce(category_id, 0)", "coalesce(folder_id, 0)", "coalesce(agent_id, 0)", "coalesce(type, 0)",
+	"coalesce(status, 0)", "coalesce(title, '')", "coalesce(tags, '{}')", "seo_data", "coalesce(attachment_ids, '{}')",
+	"coalesce(thumbs_up, 0)", "coalesce(thumbs_down, 0)", "coalesce(hits, 0)", "created_at", "updated_at",
+	"coalesce((SELECT ag.name FROM fresh.agent ag WHERE ag.domain_id = solution_article.domain_id AND ag.id = solution_article.agent_id LIMIT 1), '')"}
+
+// articleValues returns pointers to the fields of the article in the order of articleColumns.
+func articleValues(a *models.SolutionArticle) []any {
+	return []any{&a.RowID, &a.ImportedAt, &a.AWSKey, &a.DomainID, &a.ID, &a.CategoryID, &a.FolderID, &a.AgentID,
+		&a.Type, &a.Status, &a.Title, &a.Tags, &a.SEOData, &a.AttachmentIDs, &a.ThumbsUp, &a.ThumbsDown, &a.Hits,
+		&a.CreatedAt, &a.UpdatedAt, &a.AgentName}
+}
+
+// ArticleFilter represents the filter of the solution article list. Zero values don't filter.
+type ArticleFilter struct {
+	DomainID   int64
+	CategoryID int64
+	FolderID   int64
+	Status     int64
+	Tags       []string
+	Limit      uint64
+	Offset     uint64
+}
+
+// CreateSolutionCategories inserts solution categories into the fresh.solution_category table, updating existing ones.
+func (c *Connection) CreateSolutionCategories(ctx context.Context, categories []*models.SolutionCategory) error {
+	columns := []string{"aws_key", "domain_id", "id", "name", "description", "visible_in_portals", "created_at",
+		"updated_at"}
+	rows := make([]referenceRow, 0, len(categories))
+	for _, cat := range categories {
+		rows = append(rows, referenceRow{id: cat.ID, values: []any{cat.AWSKey, cat.DomainID, cat.ID, cat.Name,
+			cat.Description, cat.VisibleInPortals, cat.CreatedAt, cat.UpdatedAt}})
+	}
+
+	return c.upsertReferences(ctx, "fresh.solution_category", columns, rows)
+}
+
+// CreateSolutionFolders inserts solution folders into the fresh.solution_folder table, updating existing ones.
+func (c *Connection) CreateSolutionFolders(ctx context.Context, folders []*models.SolutionFolder) error {
+	columns := []string{"aws_key", "domain_id", "id", "category_id", "parent_folder_id", "name", "description",
+		"visibility", "company_ids", "contact_segment_ids", "company_segment_ids", "created_at", "updated_at"}
+	rows := make([]referenceRow, 0, len(folders))
+	for _, f := range folders {
+		rows = append(rows, referenceRow{id: f.ID, values: []any{f.AWSKey, f.DomainID, f.ID, f.CategoryID,
+			f.ParentFolderID, f.Name, f.Description, f.Visibility, f.CompanyIDs, f.ContactSegmentIDs,
+			f.CompanySegmentIDs, f.CreatedAt, f.UpdatedAt}})
+	}
+
+	return c.upsertReferences(ctx, "fresh.solution_folder", columns, rows)
+}
+
+// CreateSolutionArticles inserts solution articles into the fresh.solution_article table, updating existing ones,
+// and their attachments into the fresh.attachment table within transaction. Attachment rows of the previously
+// imported article are replaced, so re-importing the export doesn't duplicate them.
+func (c *Connection) CreateSolutionArticles(ctx context.Context, articles []*models.SolutionArticle) error {
+	columns := []string{"aws_key", "domain_id", "id", "category_id", "folder_id", "agent_id", "type", "status",
+		"title", "description", "description_text", "tags", "seo_data", "attachment_ids", "thumbs_up", "thumbs_down",
+		"hits", "created_at", "updated_at"}
+	suffix := upsertSuffix([]string{"domain_id", "id"}, columns)
+	fn := func(ctx context.Context, tx *ConnectionTx) error {
+		for _, a := range articles {
+			if err := tx.deleteArticleAttachments(ctx, a); err != nil {
+				return fmt.Errorf("article [%d]: %v", a.ID, err)
+			}
+
+			if err := tx.createAttachments(ctx, a.DomainID, a.Attachments); err != nil {
+				return fmt.Errorf("article [%d]: %v", a.ID, err)
+			}
+
+			attIDs := make([]int64, 0, len(a.Attachments))
+			for _, att := range a.Attachments {
+				attIDs = append(attIDs, att.ID)
+			}
+
+			sql, args, err := tx.conn.psql.Insert("fresh.solution_article").Columns(columns...).
+				Values(a.AWSKey, a.DomainID, a.ID, a.CategoryID, a.FolderID, a.AgentID, a.Type, a.Status, a.Title,
+					a.Description, a.DescriptionText, a.Tags, a.SEOData, attIDs, a.ThumbsUp, a.ThumbsDown, a.Hits,
+					a.CreatedAt, a.UpdatedAt).
+				Suffix(suffix).ToSql()
+			if err != nil {
+				return fmt.Errorf("article [%d]: build query: %v", a.ID, err)
+			}
+
+			if _, err := tx.tx.Exec(ctx, sql, args...); err != nil {
+				return fmt.Errorf("article [%d]: exec query: %v", a.ID, err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := c.WithTx(ctx, fn); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// deleteArticleAttachments deletes attachment rows of the previously imported article and rows
+// with IDs of the article attachments within transaction.
+func (c *ConnectionTx) deleteArticleAttachments(ctx context.Context, article *models.SolutionArticle) error {
+	ids := make([]int64, 0, len(article.Attachments))
+	for _, att := range article.Attachments {
+		ids = append(ids, att.ID)
+	}
+
+	sql, args, err := c.conn.psql.Delete("fresh.attachment").
+		Where(sq.Eq{"domain_id": article.DomainID}).
+		Where(sq.Or{
+			sq.Eq{"id": ids},
+			sq.Expr("id IN (SELECT unnest(attachment_ids) FROM fresh.solution_article WHERE domain_id = ? AND id = ?)",
+				article.DomainID, article.ID),
+		}).ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %v", err)
+	}
+
+	if _, err := c.tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("delete attachments: %v", err)
+	}
+
+	return nil
+}
+
+// SolutionCategories retrieves solution categories of the domain with their folders, ordered by name.
+// Folders have the number of their articles.
+func (c *Connection) SolutionCategories(ctx context.Context, domain int64) ([]*models.SolutionCategory, error) {
+	sql, args, err := c.psql.Select("row_id", "imported_at", "coalesce(aws_key, '')", "domain_id", "id",
+		"coalesce(name, '')", "coalesce(description, '')", "coalesce(visible_in_portals, '{}')", "created_at",
+		"updated_at").
+		From("fresh.solution_category").
+		Where(sq.Eq{"domain_id": domain}).
+		OrderBy("name", "id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	categories := make([]*models.SolutionCategory, 0)
+	byID := make(map[int64]*models.SolutionCategory)
+	for rows.Next() {
+		var cat models.SolutionCategory
+		if err := rows.Scan(&cat.RowID, &cat.ImportedAt, &cat.AWSKey, &cat.DomainID, &cat.ID, &cat.Name,
+			&cat.Description, &cat.VisibleInPortals, &cat.CreatedAt, &cat.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		categories = append(categories, &cat)
+		byID[cat.ID] = &cat
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	folders, err := c.SolutionFolders(ctx, domain)
+	if err != nil {
+		return nil, fmt.Errorf("folders: %w", err)
+	}
+
+	for _, f := range folders {
+		if cat, ok := byID[f.CategoryID]; ok {
+			cat.Folders = append(cat.Folders, f)
+		}
+	}
+
+	return categories, nil
+}
+
+// SolutionFolders retrieves solution folders of the domain with the number of their articles, ordered by name.
+func (c *Connection) SolutionFolders(ctx context.Context, domain int64) ([]*models.SolutionFolder, error) {
+	sql, args, err := c.psql.Select("row_id", "imported_at", "coalesce(aws_key, '')", "domain_id", "id",
+		"coalesce(category_id, 0)", "coalesce(parent_folder_id, 0)", "coalesce(name, '')", "coalesce(description, '')",
+		"coalesce(visibility, 0)", "coalesce(company_ids, '{}')", "coalesce(contact_segment_ids, '{}')",
+		"coalesce(company_segment_ids, '{}')", "created_at", "updated_at",
+		"(SELECT count(*) FROM fresh.solution_article sa WHERE sa.domain_id = solution_folder.domain_id AND sa.folder_id = solution_folder.id)").
+		From("fresh.solution_folder").
+		Where(sq.Eq{"domain_id": domain}).
+		OrderBy("name", "id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	folders := make([]*models.SolutionFolder, 0)
+	for rows.Next() {
+		var f models.SolutionFolder
+		if err := rows.Scan(&f.RowID, &f.ImportedAt, &f.AWSKey, &f.DomainID, &f.ID, &f.CategoryID, &f.ParentFolderID,
+			&f.Name, &f.Description, &f.Visibility, &f.CompanyIDs, &f.ContactSegmentIDs, &f.CompanySegmentIDs,
+			&f.CreatedAt, &f.UpdatedAt, &f.ArticlesCount); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		folders = append(folders, &f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return folders, nil
+}
+
+// SolutionArticles retrieves a page of solution articles matching the filter, recently updated first.
+// Article bodies are not selected, use SolutionArticle to get them.
+func (c *Connection) SolutionArticles(ctx context.Context, f *ArticleFilter) ([]*models.SolutionArticle, error) {
+	q := c.psql.Select(articleColumns...).
+		From("fresh.solution_article").
+		Where(sq.Eq{"domain_id": f.DomainID})
+	if f.CategoryID != 0 {
+		q = q.Where(sq.Eq{"category_id": f.CategoryID})
+	}
+
+	if f.FolderID != 0 {
+		q = q.Where(sq.Eq{"folder_id": f.FolderID})
+	}
+
+	if f.Status != 0 {
+		q = q.Where(sq.Eq{"status": f.Status})
+	}
+
+	if len(f.Tags) > 0 {
+		q = q.Where(sq.Expr("tags @> ?", f.Tags))
+	}
+
+	sql, args, err := q.OrderBy("updated_at DESC NULLS LAST", "id DESC").Limit(f.Limit).Offset(f.Offset).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	articles := make([]*models.SolutionArticle, 0)
+	for rows.Next() {
+		var a models.SolutionArticle
+		if err := rows.Scan(articleValues(&a)...); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		articles = append(articles, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return articles, nil
+}
+
+// SolutionArticle retrieves the solution article with its body and attachment metadata.
+func (c *Connection) SolutionArticle(ctx context.Context, domain, id int64) (*models.SolutionArticle, error) {
+	sql, args, err := c.psql.Select(append(articleColumns, "coalesce(description, '')",
+		"coalesce(description_text, '')")...).
+		From("fresh.solution_article").
+		Where(sq.Eq{"domain_id": domain, "id": id}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	var a models.SolutionArticle
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(append(articleValues(&a), &a.Description,
+		&a.DescriptionText)...); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	attachments, err := c.Attachments(ctx, domain, a.AttachmentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("attachments: %w", err)
+	}
+
+	byID := make(map[int64]*models.Attachment, len(attachments))
+	for _, att := range attachments {
+		byID[att.ID] = att
+	}
+
+	a.Attachments = pickAttachments(byID, a.AttachmentIDs)
+
+	return &a, nil
+}
+
+// searchArticlesQuery ranks articles of the domain matched by the title, tags or body, using the text search
+// configuration of the domain and `websearch_to_tsquery` syntax, like the ticket search.
+const searchArticlesQuery = `
+WITH q AS (SELECT websearch_to_tsquery(fresh.domain_language($1), $2) query, fresh.domain_language($1) cfg)
+SELECT a.id, coalesce(a.folder_id, 0), coalesce(a.category_id, 0), coalesce(a.title, ''), coalesce(a.status, 0),
+       a.updated_at, ts_rank(a.search_vector, q.query)::real rank,
+       ts_headline(q.cfg, fresh.html_escape(coalesce(a.title, '')), q.query, $5),
+       ts_headline(q.cfg, fresh.html_escape(coalesce(a.description_text, '')), q.query, $5)
+FROM fresh.solution_article a,
+     q
+WHERE a.domain_id = $1
+  AND a.search_vector @@ q.query
+ORDER BY rank DESC, a.id DESC
+LIMIT $3 OFFSET $4`
+
+// SearchArticles performs the full-text search over solution article titles, tags and bodies of the given domain
+// and returns articles ordered by rank with highlighted fragments.
+func (c *Connection) SearchArticles(ctx context.Context, domain int64, q string, limit, offset uint64) ([]*models.ArticleSearchResult, error) {
+	rows, err := c.pool.Query(ctx, searchArticlesQuery, domain, q, limit, offset, headlineOptions)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	results := make([]*models.ArticleSearchResult, 0)
+	for rows.Next() {
+		var r models.ArticleSearchResult
+		if err := rows.Scan(&r.ArticleID, &r.FolderID, &r.CategoryID, &r.Title, &r.Status, &r.UpdatedAt, &r.Rank,
+			&r.TitleHighlight, &r.DescriptionHighlight); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		results = append(results, &r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return results, nil
+}
@@ -2,11 +2,15 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 
 	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/ticketfield"
 )
 
 // Ticket retrieves a row_id from the `fresh.ticket_raw` table based on given domain ID and AWS key.
@@ -29,10 +33,22 @@ func (c *Connection) Ticket(ctx context.Context, domain int64, key string) (bool
 }
 
 // CreateTicket calls a function `fn` within a transaction on a ConnectionTx instance.
-// It first creates conversations, then attachments, then the ticket itself,
-// and finally the raw ticket. If any error occurs, it returns the error.
+// It first creates conversations, then attachments, then the ticket itself, its links to associated tickets
+// and finally the raw ticket. Parent ID of the ticket and its conversations is the parent or tracker ticket,
+// derived from the association type. If any error occurs, it returns the error.
 // If successful, it commits the transaction.
 func (c *Connection) CreateTicket(ctx context.Context, ticket *models.Ticket) error {
+	associations := ticketAssociations(ticket)
+	if id := parentID(ticket, associations); id != 0 {
+		ticket.ParentID = id
+	}
+
+	for _, cc := range ticket.Conversations {
+		if cc.ParentID == 0 {
+			cc.ParentID = ticket.ParentID
+		}
+	}
+
 	fn := func(ctx context.Context, tx *ConnectionTx) error {
 		if err := tx.createConversations(ctx, ticket.DomainID, ticket.Conversations); err != nil {
 			return fmt.Errorf("ticket [%d](%d): %v", ticket.ID, ticket.RequesterID, err)
@@ -46,6 +62,10 @@ func (c *Connection) CreateTicket(ctx context.Context, ticket *models.Ticket) er
 			return fmt.Errorf("ticket [%d](%d): %v", ticket.ID, ticket.RequesterID, err)
 		}
 
+		if err := tx.createAssociations(ctx, ticket, associations); err != nil {
+			return fmt.Errorf("associations [%d](%d): %v", ticket.ID, ticket.RequesterID, err)
+		}
+
 		if err := tx.createRAWTicket(ctx, ticket.DomainID, ticket.AWSKey, ticket.ID, ticket.RequesterID, ticket.Raw); err != nil {
 			return fmt.Errorf("raw [%d](%d): %v", ticket.ID, ticket.RequesterID, err)
 		}
@@ -70,7 +90,7 @@ func (c *ConnectionTx) createTicket(ctx context.Context, ticket *models.Ticket)
 	}
 
 	values := map[string]interface{}{
-		"aws_key": ticket.AWSKey, "id": ticket.ID, "archived": ticket.Archived, "meta": ticket.Meta, "name": ticket.Name,
+		"aws_key": ticket.AWSKey, "id": ticket.ID, "parent_id": nullID(ticket.ParentID), "archived": ticket.Archived, "meta": ticket.Meta, "name": ticket.Name,
 		"cc_emails": ticket.CCEmails, "ticket_cc_emails": ticket.TicketCCEmails, "company_id": ticket.CompanyID,
 		"custom_fields": ticket.CustomFields, "deleted": ticket.Deleted, "description": ticket.Description,
 		"description_text": ticket.DescriptionText, "due_by": ticket.DueBy, "email": ticket.Email,
@@ -102,6 +122,7 @@ func (c *ConnectionTx) createTicket(ctx context.Context, ticket *models.Ticket)
 
 // createRAWTicket inserts a new raw ticket json record into the fresh.ticket_raw table within transaction.
 // The method takes the domain, key, id, requesterID, and ticket as arguments and inserts them into the table.
+// The ticket is stored both as jsonb, to query it, and as text exactly as imported.
 // If any error occurs during the process, it returns the error.
 func (c *ConnectionTx) createRAWTicket(ctx context.Context, domain int64, key string, id, requesterID int64, ticket []byte) error {
 	values := map[string]interface{}{
@@ -109,6 +130,7 @@ func (c *ConnectionTx) createRAWTicket(ctx context.Context, domain int64, key st
 		"ticket_id":    id,
 		"requester_id": requesterID,
 		"ticket":       ticket,
+		"ticket_text":  string(ticket),
 		"domain_id":    domain,
 	}
 
@@ -153,6 +175,7 @@ func (c *ConnectionTx) createConversation(ctx context.Context, domain int64, con
 	values := map[string]interface{}{
 		"id":                     conversation.Id,
 		"ticket_id":              conversation.TicketID,
+		"parent_id":              nullID(conversation.ParentID),
 		"body":                   conversation.Body,
 		"body_text":              conversation.BodyText,
 		"incoming":               conversation.Incoming,
@@ -233,3 +256,304 @@ func (c *ConnectionTx) createAttachment(ctx context.Context, domain int64, attac
 
 	return nil
 }
+
+// TicketFilter represents conditions and pagination of the ticket list.
+// Empty fields disable the corresponding condition.
+//
+// CreatedFrom and CreatedTo limit the creation time window, the upper bound is exclusive.
+//
+// Subject is a case-insensitive substring of the ticket subject.
+//
+// Tags lists tags the ticket must have all of.
+//
+// Asc sorts tickets by creation time in ascending order, instead of descending.
+//
+// After is the cursor: tickets following the (created_at, id) pair in the sort order are returned.
+// Tickets without the creation time sort after all others, the same way as NULL does: first in descending
+// order and last in ascending order.
+type TicketFilter struct {
+	DomainID      int64
+	Statuses      []int64
+	Priorities    []int64
+	Sources       []int64
+	RequesterName string
+	Tags          []string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	Subject       string
+	Asc           bool
+	After         *TicketCursor
+	Limit         uint64
+}
+
+// TicketCursor represents a position in the ticket list sorted by creation time and ID.
+// CreatedAt is nil if the ticket has no creation time.
+type TicketCursor struct {
+	CreatedAt *time.Time
+	ID        int64
+}
+
+// Tickets retrieves summaries of tickets from the `fresh.ticket` table matching the given filter.
+// Tickets are sorted by (created_at, id), which follows the `ticket_created_at_idx` index scanned forward
+// for the descending order and backward for the ascending one. The cursor pages through tickets without
+// the creation time too.
+func (c *Connection) Tickets(ctx context.Context, f *TicketFilter) ([]*models.Ticket, error) {
+	order := "DESC"
+	if f.Asc {
+		order = "ASC"
+	}
+
+	query := c.psql.Select("domain_id", "id", "coalesce(subject, '')", "coalesce(status, 0)", "coalesce(priority, 0)",
+		"coalesce(source, 0)", "coalesce(type, '')", "coalesce(requester_id, 0)", "coalesce(requester_name, '')",
+		"coalesce(email, '')", "coalesce(responder_id, 0)", "coalesce(group_id, 0)", "coalesce(company_id, 0)", "tags",
+		"coalesce(spam, false)", "coalesce(deleted, false)", "created_at", "updated_at", "imported_at").
+		From("fresh.ticket").
+		Where(sq.Eq{"domain_id": f.DomainID}).
+		OrderBy("created_at "+order, "id "+order).
+		Limit(f.Limit)
+
+	if len(f.Statuses) > 0 {
+		query = query.Where(sq.Eq{"status": f.Statuses})
+	}
+
+	if len(f.Priorities) > 0 {
+		query = query.Where(sq.Eq{"priority": f.Priorities})
+	}
+
+	if len(f.Sources) > 0 {
+		query = query.Where(sq.Eq{"source": f.Sources})
+	}
+
+	if f.RequesterName != "" {
+		query = query.Where(sq.Eq{"requester_name": f.RequesterName})
+	}
+
+	if len(f.Tags) > 0 {
+		query = query.Where(sq.Expr("tags @> ?", f.Tags))
+	}
+
+	if f.CreatedFrom != nil {
+		query = query.Where(sq.GtOrEq{"created_at": f.CreatedFrom})
+	}
+
+	if f.CreatedTo != nil {
+		query = query.Where(sq.Lt{"created_at": f.CreatedTo})
+	}
+
+	if f.Subject != "" {
+		query = query.Where(sq.ILike{"subject": "%" + escapeLike(f.Subject) + "%"})
+	}
+
+	if f.After != nil {
+		// NULL creation time is greater than any other, so tickets without it follow the others
+		// in ascending order and precede them in descending order
+		switch {
+		case f.After.CreatedAt != nil && f.Asc:
+			query = query.Where(sq.Or{
+				sq.Expr("(created_at, id) > (?, ?)", f.After.CreatedAt, f.After.ID),
+				sq.Eq{"created_at": nil},
+			})
+		case f.After.CreatedAt != nil:
+			query = query.Where(sq.Expr("(created_at, id) < (?, ?)", f.After.CreatedAt, f.After.ID))
+		case f.Asc:
+			query = query.Where(sq.And{sq.Eq{"created_at": nil}, sq.Expr("id > ?", f.After.ID)})
+		default:
+			query = query.Where(sq.Or{
+				sq.And{sq.Eq{"created_at": nil}, sq.Expr("id < ?", f.After.ID)},
+				sq.NotEq{"created_at": nil},
+			})
+		}
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	tickets := make([]*models.Ticket, 0)
+	for rows.Next() {
+		var t models.Ticket
+		if err := rows.Scan(&t.DomainID, &t.ID, &t.Subject, &t.Status, &t.Priority, &t.Source, &t.Type, &t.RequesterID,
+			&t.RequesterName, &t.Email, &t.ResponderID, &t.GroupID, &t.CompanyID, &t.Tags, &t.Spam, &t.Deleted,
+			&t.CreatedAt, &t.UpdatedAt, &t.ImportedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		tickets = append(tickets, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return tickets, nil
+}
+
+// escapeLike escapes LIKE pattern wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// TicketByID retrieves the ticket row from the `fresh.ticket` table by the given domain and ticket ID
+// with status, priority and source names. If the ticket was imported several times, the latest imported row is returned.
+func (c *Connection) TicketByID(ctx context.Context, domain, id int64) (*models.Ticket, error) {
+	sql, args, err := c.psql.Select("row_id", "imported_at", "coalesce(aws_key, '')", "domain_id",
+		"coalesce(requester_name, '')", "id", "coalesce(parent_id, 0)", "coalesce(archived, false)", "meta", "coalesce(name, '')", "cc_emails",
+		"ticket_cc_emails", "coalesce(company_id, 0)", "custom_fields", "coalesce(deleted, false)",
+		"coalesce(description, '')", "coalesce(description_text, '')", "due_by", "coalesce(email, '')",
+		"coalesce(email_config_id, 0)", "coalesce(facebook_id, '')", "fr_due_by", "coalesce(fr_escalated, false)",
+		"nr_due_by", "coalesce(nr_escalated, false)", "fwd_emails", "coalesce(group_id, 0)",
+		"coalesce(is_escalated, false)", "coalesce(phone, '')", "coalesce(priority, 0)", "coalesce(product_id, 0)",
+		"reply_cc_emails", "coalesce(requester_id, 0)", "coalesce(responder_id, 0)", "coalesce(source, 0)",
+		"coalesce(spam, false)", "coalesce(status, 0)", "coalesce(subject, '')", "tags", "to_emails",
+		"coalesce(twitter_id, '')", "coalesce(type, '')", "created_at", "updated_at", "coalesce(association_type, 0)",
+		"coalesce(source_additional_info, '')", "coalesce(support_email, '')", "coalesce(form_id, 0)",
+		"attachment_ids",
+		"coalesce((SELECT s.name FROM fresh.ticket_status s WHERE s.value = ticket.status LIMIT 1), '')",
+		"coalesce((SELECT p.name FROM fresh.ticket_priority p WHERE p.value = ticket.priority LIMIT 1), '')",
+		"coalesce((SELECT so.name FROM fresh.ticket_source so WHERE so.value = ticket.source LIMIT 1), '')",
+		"coalesce((SELECT co.name FROM fresh.company co WHERE co.domain_id = ticket.domain_id AND co.id = ticket.company_id LIMIT 1), '')",
+		"coalesce((SELECT ag.name FROM fresh.agent ag WHERE ag.domain_id = ticket.domain_id AND ag.id = ticket.responder_id LIMIT 1), '')",
+		"coalesce((SELECT g.name FROM fresh.agent_group g WHERE g.domain_id = ticket.domain_id AND g.id = ticket.group_id LIMIT 1), '')").
+		From("fresh.ticket").
+		Where(sq.Eq{"domain_id": domain, "id": id}).
+		OrderBy("row_id DESC").
+		Limit(1).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	var t models.Ticket
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&t.RowID, &t.ImportedAt, &t.AWSKey, &t.DomainID,
+		&t.RequesterName, &t.ID, &t.ParentID, &t.Archived, &t.Meta, &t.Name, &t.CCEmails, &t.TicketCCEmails, &t.CompanyID,
+		&t.CustomFields, &t.Deleted, &t.Description, &t.DescriptionText, &t.DueBy, &t.Email, &t.EmailConfigID,
+		&t.FacebookID, &t.FrDueBy, &t.FrEscalated, &t.NrDueBy, &t.NrEscalated, &t.FwdEmails, &t.GroupID,
+		&t.IsEscalated, &t.Phone, &t.Priority, &t.ProductID, &t.ReplyCCEmails, &t.RequesterID, &t.ResponderID,
+		&t.Source, &t.Spam, &t.Status, &t.Subject, &t.Tags, &t.ToEmails, &t.TwitterID, &t.Type, &t.CreatedAt,
+		&t.UpdatedAt, &t.AssociationType, &t.SourceAdditionalInfo, &t.SupportEmail, &t.FormID,
+		&t.AttachmentIDs, &t.StatusName, &t.PriorityName, &t.SourceName, &t.CompanyName, &t.ResponderName,
+		&t.GroupName); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	return &t, nil
+}
+
+// TicketDetails retrieves the ticket with its conversations and attachment metadata,
+// the same way the Grafana ticket dashboard assembles it from `attachment_ids` of the ticket
+// and its conversations, linked tickets, time entries, satisfaction ratings and the requester contact if it is imported.
+// Custom fields are decoded with the imported ticket field definitions.
+func (c *Connection) TicketDetails(ctx context.Context, domain, id int64) (*models.Ticket, error) {
+	ticket, err := c.TicketByID(ctx, domain, id)
+	if err != nil {
+		return nil, err
+	}
+
+	conversations, err := c.Conversations(ctx, domain, id)
+	if err != nil {
+		return nil, fmt.Errorf("conversations: %w", err)
+	}
+
+	ids := append([]int64{}, ticket.AttachmentIDs...)
+	for _, cc := range conversations {
+		ids = append(ids, cc.AttachmentIDs...)
+	}
+
+	attachments, err := c.Attachments(ctx, domain, ids)
+	if err != nil {
+		return nil, fmt.Errorf("attachments: %w", err)
+	}
+
+	byID := make(map[int64]*models.Attachment, len(attachments))
+	for _, a := range attachments {
+		byID[a.ID] = a
+	}
+
+	ticket.Attachments = pickAttachments(byID, ticket.AttachmentIDs)
+	for _, cc := range conversations {
+		cc.Attachments = pickAttachments(byID, cc.AttachmentIDs)
+	}
+
+	ticket.Conversations = conversations
+	fields, err := c.TicketFields(ctx, domain)
+	if err != nil {
+		return nil, fmt.Errorf("ticket fields: %w", err)
+	}
+
+	ticket.CustomFieldValues = ticketfield.New(fields).Resolve(ticket.CustomFields)
+	if ticket.LinkedTickets, err = c.LinkedTickets(ctx, domain, id); err != nil {
+		return nil, fmt.Errorf("linked tickets: %w", err)
+	}
+
+	if ticket.TimeEntries, err = c.TimeEntries(ctx, domain, id); err != nil {
+		return nil, fmt.Errorf("time entries: %w", err)
+	}
+
+	if ticket.SatisfactionRatings, err = c.SatisfactionRatings(ctx, domain, id); err != nil {
+		return nil, fmt.Errorf("satisfaction ratings: %w", err)
+	}
+
+	if ticket.RequesterID != 0 {
+		requester, err := c.Contact(ctx, domain, ticket.RequesterID)
+		if err != nil && !errors.Is(err, ErrDBNoExists) {
+			return nil, fmt.Errorf("requester: %w", err)
+		}
+
+		ticket.Requester = requester
+	}
+
+	return ticket, nil
+}
+
+// nullID returns nil for the zero ID, so the column is NULL rather than 0.
+func nullID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+
+	return id
+}
+
+// pickAttachments returns attachments with the given IDs in the same order, skipping unknown ones.
+func pickAttachments(attachments map[int64]*models.Attachment, ids []int64) []*models.Attachment {
+	list := make([]*models.Attachment, 0, len(ids))
+	for _, id := range ids {
+		if a, ok := attachments[id]; ok {
+			list = append(list, a)
+		}
+	}
+
+	return list
+}
+
+// RawTicket retrieves the original ticket JSON from the `fresh.ticket_raw` table by the given domain and ticket ID.
+// An empty path selects the whole document exactly as imported, tickets imported before the text was stored
+// are returned from the jsonb column. Otherwise, the path selects a nested value the same way as the `#>`
+// operator does. It returns nil if the path doesn't exist in the document.
+func (c *Connection) RawTicket(ctx context.Context, domain, id int64, path []string) ([]byte, error) {
+	column := sq.Expr("coalesce(ticket_text, ticket::text)")
+	if len(path) > 0 {
+		column = sq.Expr("(ticket #> ?)::text", path)
+	}
+
+	sql, args, err := c.psql.Select().Column(column).From("fresh.ticket_raw").
+		Where(sq.Eq{"domain_id": domain, "ticket_id": id}).
+		OrderBy("row_id DESC").
+		Limit(1).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	var raw []byte
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// CreateTimeEntries inserts time entries into the fresh.time_entry table, updating existing ones.
+func (c *Connection) CreateTimeEntries(ctx context.Context, entries []*models.TimeEntry) error {
+	columns := []string{"aws_key", "domain_id", "id", "ticket_id", "agent_id", "company_id", "billable", "note",
+		"time_spent", "time_spent_minutes", "timer_running", "executed_at", "start_time", "created_at", "updated_at"}
+	rows := make([]referenceRow, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, referenceRow{id: e.ID, values: []any{e.AWSKey, e.DomainID, e.ID, e.TicketID, e.AgentID,
+			e.CompanyID, e.Billable, e.Note, e.TimeSpent, e.TimeSpentMinutes, e.TimerRunning, e.ExecutedAt,
+			e.StartTime, e.CreatedAt, e.UpdatedAt}})
+	}
+
+	return c.upsertReferences(ctx, "fresh.time_entry", columns, rows)
+}
+
+// TimeEntries retrieves time entries of the given ticket ordered by execution time, with names of the agents.
+func (c *Connection) TimeEntries(ctx context.Context, domain, ticket int64) ([]*models.TimeEntry, error) {
+	sql, args, err := c.psql.Select("row_id", "imported_at", "coalesce(aws_key, '')", "domain_id", "id",
+		"ticket_id", "coalesce(agent_id, 0)", "coalesce(company_id, 0)", "coalesce(billable, false)",
+		"coalesce(note, '')", "coalesce(time_spent, '')", "coalesce(time_spent_minutes, 0)",
+		"coalesce(timer_running, false)", "executed_at", "start_time", "created_at", "updated_at",
+		"coalesce((SELECT ag.name FROM fresh.agent ag WHERE ag.domain_id = time_entry.domain_id AND ag.id = time_entry.agent_id LIMIT 1), '')").
+		From("fresh.time_entry").
+		Where(sq.Eq{"domain_id": domain, "ticket_id": ticket}).
+		OrderBy("executed_at", "id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	entries := make([]*models.TimeEntry, 0)
+	for rows.Next() {
+		var e models.TimeEntry
+		if err := rows.Scan(&e.RowID, &e.ImportedAt, &e.AWSKey, &e.DomainID, &e.ID, &e.TicketID, &e.AgentID,
+			&e.CompanyID, &e.Billable, &e.Note, &e.TimeSpent, &e.TimeSpentMinutes, &e.TimerRunning, &e.ExecutedAt,
+			&e.StartTime, &e.CreatedAt, &e.UpdatedAt, &e.AgentName); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// CreateToken inserts a new API token with the given name, token hash and allowed domains into the fresh.api_token table.
+// Empty domains grant access to all domains.
+func (c *Connection) CreateToken(ctx context.Context, name, hash string, domains []int64) (int64, error) {
+	sql, args, err := c.psql.Insert("fresh.api_token").
+		SetMap(map[string]interface{}{"name": name, "token_hash": hash, "domain_ids": domains}).
+		Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build query: %v", err)
+	}
+
+	var id int64
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return id, nil
+}
+
+// Tokens returns all API tokens, including revoked ones, ordered by ID.
+func (c *Connection) Tokens(ctx context.Context) ([]*models.Token, error) {
+	sql, args, err := c.psql.Select("id", "name", "domain_ids", "created_at", "revoked_at").
+		From("fresh.api_token").OrderBy("id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	rows, err := c.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+	tokens := make([]*models.Token, 0)
+	for rows.Next() {
+		var t models.Token
+		if err := rows.Scan(&t.ID, &t.Name, &t.DomainIDs, &t.CreatedAt, &t.RevokedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		tokens = append(tokens, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// TokenByHash retrieves the active (not revoked) API token by its hash.
+func (c *Connection) TokenByHash(ctx context.Context, hash string) (*models.Token, error) {
+	sql, args, err := c.psql.Select("id", "name", "domain_ids", "created_at").From("fresh.api_token").
+		Where(sq.Eq{"token_hash": hash, "revoked_at": nil}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %v", err)
+	}
+
+	var t models.Token
+	if err := c.pool.QueryRow(ctx, sql, args...).Scan(&t.ID, &t.Name, &t.DomainIDs, &t.CreatedAt); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	return &t, nil
+}
+
+// RevokeToken marks the active API token with the given name as revoked.
+func (c *Connection) RevokeToken(ctx context.Context, name string) error {
+	sql, args, err := c.psql.Update("fresh.api_token").Set("revoked_at", sq.Expr("now()")).
+		Where(sq.Eq{"name": name, "revoked_at": nil}).ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %v", err)
+	}
+
+	tag, err := c.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("exec query: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrDBNoExists
+	}
+
+	return nil
+}
@@ -1,6 +1,18 @@
 package filestorage
 
-import "os"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SolutionsDir is the directory of solution article attachments in the attachment directory of the domain.
+const SolutionsDir = "solutions"
 
 func InsureDir(fp string) error {
 	if IsExist(fp) {
@@ -18,7 +30,105 @@ func IsExist(fp string) bool {
 	return err == nil || os.IsExist(err)
 }
 
+// CheckDir checks that the directory exists and can be read.
+func CheckDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Readdirnames(1); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // Remove one file
 func Remove(name string) error {
 	return os.Remove(name)
 }
+
+// RemoveAll removes path and any children it contains
+func RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+// Rename renames (moves) oldpath to newpath
+func Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// Checksum returns the hex-encoded SHA-256 checksum of the file content
+func Checksum(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("read file: %v", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// DomainDir returns the attachment directory of the domain: <dir>/<domain>. It fails unless the directory
+// is a strict child of dir, so removing or renaming it never touches the attachment root or paths outside it
+func DomainDir(dir, domain string) (string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := filepath.Abs(filepath.Join(dir, domain))
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", err
+	}
+
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("attachment directory of domain %q is not inside %s", domain, dir)
+	}
+
+	return path, nil
+}
+
+// AttachmentPath returns the path of the stored attachment file: <dir>/<domain>/<ticket id>/<id>[.ext],
+// where the extension is taken from the original attachment name
+func AttachmentPath(dir, domain string, ticketID, id int64, name string) string {
+	fileName := strconv.FormatInt(id, 10) + filepath.Ext(name)
+
+	return filepath.Join(dir, domain, strconv.FormatInt(ticketID, 10), fileName)
+}
+
+// ArticleAttachmentPath returns the path of the stored solution article attachment file:
+// <dir>/<domain>/solutions/<article id>/<id>[.ext], next to the ticket attachments of the domain
+func ArticleAttachmentPath(dir, domain string, articleID, id int64, name string) string {
+	fileName := strconv.FormatInt(id, 10) + filepath.Ext(name)
+
+	return filepath.Join(dir, domain, SolutionsDir, strconv.FormatInt(articleID, 10), fileName)
+}
+
+// HumanSize returns a human-readable representation of the size in bytes, e.g. 12.3 MiB
+func HumanSize(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
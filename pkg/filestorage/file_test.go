@@ -0,0 +1,43 @@
+package filestorage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDomainDir(t *testing.T) {
+	root := t.TempDir()
+	for _, tt := range []struct {
+		domain string
+		ok     bool
+	}{
+		{domain: "acme", ok: true},
+		{domain: "acme/sub", ok: true},
+		{domain: "..acme", ok: true},
+		{domain: ""},
+		{domain: "."},
+		{domain: ".."},
+		{domain: "../x"},
+		{domain: "acme/../.."},
+		{domain: "acme/.."},
+	} {
+		dir, err := DomainDir(root, tt.domain)
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("domain %q: got %s, want an error", tt.domain, dir)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("domain %q: %v", tt.domain, err)
+
+			continue
+		}
+
+		if want := filepath.Join(root, tt.domain); dir != want {
+			t.Errorf("domain %q: got %s, want %s", tt.domain, dir, want)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package httpserver
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -14,22 +16,28 @@ import (
 	"github.com/kirychukyurii/fd-import/config"
 	"github.com/kirychukyurii/fd-import/models"
 	"github.com/kirychukyurii/fd-import/pkg/db"
+	"github.com/kirychukyurii/fd-import/pkg/filestorage"
 )
 
 type Attachment struct {
-	cfg    *config.Config
-	log    *wlog.Logger
-	dbpool *db.Connection
+	cfg     *config.Config
+	log     *wlog.Logger
+	dbpool  *db.Connection
+	metrics *metrics
 }
 
-func NewAttachmentHandler(cfg *config.Config, log *wlog.Logger, dbpool *db.Connection) *Attachment {
+func NewAttachmentHandler(cfg *config.Config, log *wlog.Logger, dbpool *db.Connection, metrics *metrics) *Attachment {
 	return &Attachment{
-		cfg:    cfg,
-		log:    log,
-		dbpool: dbpool,
+		cfg:     cfg,
+		log:     log,
+		dbpool:  dbpool,
+		metrics: metrics,
 	}
 }
 
+// Attachment streams the attachment file. It supports byte ranges and conditional requests
+// using the file modification time and the strong ETag built from the file checksum.
+// The `disposition` query parameter selects inline or attachment (default) Content-Disposition.
 func (a *Attachment) Attachment(w http.ResponseWriter, req *http.Request) {
 	domainID, err := parseInt(req.PathValue("domain_id"))
 	if err != nil {
@@ -38,7 +46,7 @@ func (a *Attachment) Attachment(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	_, err = parseInt(req.PathValue("ticket_id"))
+	ticketID, err := parseInt(req.PathValue("ticket_id"))
 	if err != nil {
 		JSON(w, Error{Msg: "ticket id is invalid"}, http.StatusBadRequest)
 
@@ -54,6 +62,59 @@ func (a *Attachment) Attachment(w http.ResponseWriter, req *http.Request) {
 
 	attachment, err := a.dbpool.Attachment(req.Context(), domainID, id)
 	if err != nil {
+		if errors.Is(err, db.ErrDBNoExists) {
+			JSON(w, Error{Msg: "attachment not found"}, http.StatusNotFound)
+
+			return
+		}
+
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	domain, err := a.dbpool.Domain(req.Context(), &models.Domain{ID: domainID})
+	if err != nil {
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	a.serve(w, req, domainID, attachment, filestorage.AttachmentPath(a.cfg.AttachmentDir, domain.Name, ticketID,
+		attachment.ID, attachment.Name))
+}
+
+// ArticleAttachment streams the solution article attachment file the same way as Attachment does.
+func (a *Attachment) ArticleAttachment(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	articleID, err := parseInt(req.PathValue("article_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "article id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	id, err := parseInt(req.PathValue("id"))
+	if err != nil {
+		JSON(w, Error{Msg: "attachment id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	attachment, err := a.dbpool.Attachment(req.Context(), domainID, id)
+	if err != nil {
+		if errors.Is(err, db.ErrDBNoExists) {
+			JSON(w, Error{Msg: "attachment not found"}, http.StatusNotFound)
+
+			return
+		}
+
 		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
 
 		return
@@ -66,37 +127,82 @@ func (a *Attachment) Attachment(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	fileExt := filepath.Ext(attachment.Name)
-	fileName := strconv.FormatInt(attachment.ID, 10)
-	if fileExt != "" {
-		fileName = fmt.Sprintf("%d%s", attachment.ID, fileExt)
+	a.serve(w, req, domainID, attachment, filestorage.ArticleAttachmentPath(a.cfg.AttachmentDir, domain.Name,
+		articleID, attachment.ID, attachment.Name))
+}
+
+// serve streams the stored attachment file, see Attachment. Bytes of successful responses are counted
+// in the attachment metrics of the domain.
+func (a *Attachment) serve(w http.ResponseWriter, req *http.Request, domainID int64, attachment *models.Attachment, file string) {
+	disposition := req.URL.Query().Get("disposition")
+	switch disposition {
+	case "":
+		disposition = "attachment"
+	case "attachment", "inline":
+	default:
+		JSON(w, Error{Msg: "disposition is invalid, use inline or attachment"}, http.StatusBadRequest)
+
+		return
 	}
 
-	file := filepath.Join(a.cfg.AttachmentDir, domain.Name, req.PathValue("ticket_id"), fileName)
-	f, err := getFile(file)
+	f, err := os.Open(file)
 	if err != nil {
-		JSON(w, Error{Msg: fmt.Sprintf("get file: %s", err)}, http.StatusInternalServerError)
+		if errors.Is(err, fs.ErrNotExist) {
+			JSON(w, Error{Msg: "attachment file not found"}, http.StatusNotFound)
+
+			return
+		}
+
+		JSON(w, Error{Msg: fmt.Sprintf("open file: %s", err)}, http.StatusInternalServerError)
 
 		return
 	}
 
-	headers := http.Header{}
-	headers.Add("Content-Type", attachment.ContentType)
-	headers.Add("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", encodeURIComponent(attachment.Name)))
-	if w.Header().Get("Content-Encoding") == "" {
-		headers.Add("Content-Length", strconv.FormatInt(attachment.FileSize, 10))
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		JSON(w, Error{Msg: fmt.Sprintf("stat file: %s", err)}, http.StatusInternalServerError)
+
+		return
 	}
 
-	File(w, f, http.StatusOK, headers)
+	checksum := attachment.Checksum
+	if checksum == "" {
+		checksum, err = a.checksum(req.Context(), domainID, attachment.ID, file)
+		if err != nil {
+			JSON(w, Error{Msg: fmt.Sprintf("checksum: %s", err)}, http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=\"%s\"; filename*=UTF-8''%s", disposition,
+		encodeURIComponent(attachment.Name), encodeURIComponent(attachment.Name)))
+	w.Header().Set("ETag", fmt.Sprintf("\"%s\"", checksum))
+
+	// ServeContent handles Range, If-Range, If-None-Match and If-Modified-Since headers
+	// and streams the file without reading it into memory
+	sw := &statusWriter{ResponseWriter: w}
+	http.ServeContent(sw, req, attachment.Name, stat.ModTime(), f)
+	if status := sw.status(); status == http.StatusOK || status == http.StatusPartialContent {
+		a.metrics.attachmentServed(domainID, sw.bytes)
+	}
 }
 
-func getFile(file string) ([]byte, error) {
-	f, err := os.ReadFile(file)
+// checksum computes the checksum of the attachment file, which wasn't stored on import,
+// and stores it for the next requests.
+func (a *Attachment) checksum(ctx context.Context, domainID, id int64, file string) (string, error) {
+	checksum, err := filestorage.Checksum(file)
 	if err != nil {
-		return nil, fmt.Errorf("read file: %v", err)
+		return "", err
+	}
+
+	if err := a.dbpool.SetAttachmentChecksum(ctx, domainID, id, checksum); err != nil {
+		a.log.Warn("set attachment checksum", wlog.Err(err), wlog.Int64("attachment", id))
 	}
 
-	return f, nil
+	return checksum, nil
 }
 
 func parseInt(s string) (int64, error) {
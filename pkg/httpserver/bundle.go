@@ -0,0 +1,64 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/bundle"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+)
+
+// Bundle streams the zip archive with the raw ticket JSON, the HTML transcript and all ticket attachments.
+// The archive is built on the fly, so errors after the response has started are only logged.
+func (t *Ticket) Bundle(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	ticketID, err := parseInt(req.PathValue("ticket_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "ticket id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	domain, err := t.dbpool.Domain(req.Context(), &models.Domain{ID: domainID})
+	if err != nil {
+		if errors.Is(err, db.ErrDBNoExists) {
+			JSON(w, Error{Msg: "domain not found"}, http.StatusNotFound)
+
+			return
+		}
+
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	b, err := bundle.Load(req.Context(), t.log, t.dbpool, t.cfg.AttachmentDir, domain, ticketID)
+	if err != nil {
+		if errors.Is(err, db.ErrDBNoExists) {
+			JSON(w, Error{Msg: "ticket not found"}, http.StatusNotFound)
+
+			return
+		}
+
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", b.Name()))
+	w.WriteHeader(http.StatusOK)
+	if err := b.Write(w); err != nil {
+		t.log.Error("write bundle", wlog.Err(err), wlog.Int64("domain", domainID), wlog.Int64("ticket", ticketID))
+	}
+}
@@ -6,11 +6,38 @@ import (
 	"github.com/kirychukyurii/fd-import/pkg/db"
 )
 
+// Route patterns of attachment downloads.
+const (
+	attachmentRoute        = "GET /{domain_id}/ticket/{ticket_id}/attachments/{id}"
+	articleAttachmentRoute = "GET /{domain_id}/solutions/articles/{article_id}/attachments/{id}"
+)
+
 func (s *Server) RegisterHandlers(dbpool *db.Connection) {
-	attachment := NewAttachmentHandler(s.cfg, s.log, dbpool)
+	s.dbpool = dbpool
+	attachment := NewAttachmentHandler(s.cfg, s.log, dbpool, s.metrics)
+	ticket := NewTicketHandler(s.cfg, s.log, dbpool, s.signer)
+	solution := NewSolutionHandler(s.cfg, s.log, dbpool)
+	health := NewHealthHandler(s.cfg, s.log, dbpool)
+	s.public.HandleFunc("GET /healthz", health.Healthz)
+	s.public.HandleFunc("GET /readyz", health.Readyz)
+	s.public.Handle("GET /metrics", s.metrics.handler())
+	s.public.HandleFunc("GET /openapi.json", OpenAPI)
+
 	s.router.HandleFunc("GET /ping", func(w http.ResponseWriter, req *http.Request) {
 		JSON(w, "ok", http.StatusOK)
 	})
 
-	s.router.HandleFunc("GET /{domain_id}/ticket/{ticket_id}/attachments/{id}", attachment.Attachment)
+	s.router.HandleFunc("GET /{domain_id}/tickets", ticket.Tickets)
+	s.router.HandleFunc("GET /{domain_id}/search", ticket.Search)
+	s.router.HandleFunc("GET /{domain_id}/ticket/{ticket_id}", ticket.Ticket)
+	s.router.HandleFunc("GET /{domain_id}/ticket/{ticket_id}/raw", ticket.RawTicket)
+	s.router.HandleFunc("GET /{domain_id}/ticket/{ticket_id}/bundle.zip", ticket.Bundle)
+	s.router.HandleFunc("GET /{domain_id}/ticket/{ticket_id}/transcript.html", ticket.Transcript)
+	s.router.HandleFunc(attachmentRoute, attachment.Attachment)
+
+	s.router.HandleFunc("GET /{domain_id}/solutions/categories", solution.Categories)
+	s.router.HandleFunc("GET /{domain_id}/solutions/articles", solution.Articles)
+	s.router.HandleFunc("GET /{domain_id}/solutions/search", solution.Search)
+	s.router.HandleFunc("GET /{domain_id}/solutions/articles/{article_id}", solution.Article)
+	s.router.HandleFunc(articleAttachmentRoute, attachment.ArticleAttachment)
 }
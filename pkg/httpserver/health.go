@@ -0,0 +1,64 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+	"github.com/kirychukyurii/fd-import/pkg/filestorage"
+)
+
+// readyCheckTimeout limits the time of all readiness checks.
+const readyCheckTimeout = 5 * time.Second
+
+// Readiness represents results of the readiness checks, failed checks contain the error message.
+type Readiness struct {
+	Database          string `json:"database"`
+	AttachmentStorage string `json:"attachment_storage"`
+}
+
+type Health struct {
+	cfg    *config.Config
+	log    *wlog.Logger
+	dbpool *db.Connection
+}
+
+func NewHealthHandler(cfg *config.Config, log *wlog.Logger, dbpool *db.Connection) *Health {
+	return &Health{
+		cfg:    cfg,
+		log:    log,
+		dbpool: dbpool,
+	}
+}
+
+// Healthz responds while the process is able to serve requests.
+func (h *Health) Healthz(w http.ResponseWriter, req *http.Request) {
+	JSON(w, "ok", http.StatusOK)
+}
+
+// Readyz responds with 200 if the database and the attachment storage are reachable, 503 otherwise.
+func (h *Health) Readyz(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), readyCheckTimeout)
+	defer cancel()
+
+	var (
+		r    = Readiness{Database: "ok", AttachmentStorage: "ok"}
+		code = http.StatusOK
+	)
+
+	if err := h.dbpool.Ping(ctx); err != nil {
+		h.log.Warn("database is not ready", wlog.Err(err))
+		r.Database, code = err.Error(), http.StatusServiceUnavailable
+	}
+
+	if err := filestorage.CheckDir(h.cfg.AttachmentDir); err != nil {
+		h.log.Warn("attachment storage is not ready", wlog.Err(err))
+		r.AttachmentStorage, code = err.Error(), http.StatusServiceUnavailable
+	}
+
+	JSON(w, r, code)
+}
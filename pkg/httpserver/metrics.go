@@ -0,0 +1,72 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "fdimport"
+
+// metrics holds the API metrics exposed on /metrics. Routes are labeled by the matched route pattern
+// rather than the request path to keep the number of series bounded.
+type metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	duration        *prometheus.HistogramVec
+	attachmentBytes *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		attachmentBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "http",
+			Name:      "attachment_bytes_total",
+			Help:      "Number of attachment bytes served by domain.",
+		}, []string{"domain"}),
+	}
+
+	m.registry.MustRegister(m.requests, m.duration, m.attachmentBytes,
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	return m
+}
+
+// observe records the processed request.
+func (m *metrics) observe(route, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(route, method, code).Inc()
+	m.duration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+// attachmentServed records bytes of the attachment served from the domain. It is called by the attachment
+// handler after the request is authenticated and the attachment is found, so only existing domains are labeled.
+func (m *metrics) attachmentServed(domain, bytes int64) {
+	if bytes > 0 {
+		m.attachmentBytes.WithLabelValues(strconv.FormatInt(domain, 10)).Add(float64(bytes))
+	}
+}
+
+// handler serves the metrics in the Prometheus exposition format.
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
@@ -1,30 +1,76 @@
 package httpserver
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/auth"
+	"github.com/kirychukyurii/fd-import/pkg/db"
 )
 
+// masterTokenName is the name of the token from the configuration.
+const masterTokenName = "master"
+
+// clientCertTokenPrefix prefixes the common name of the verified client certificate in the request token name.
+const clientCertTokenPrefix = "cert:"
+
+// attachmentPathRegexp matches the attachment download route: /{domain_id}/ticket/{ticket_id}/attachments/{id}.
+var attachmentPathRegexp = regexp.MustCompile(`^/(\d+)/ticket/(\d+)/attachments/(\d+)$`)
+
+type tokenCtxKey struct{}
+
+type requestIDCtxKey struct{}
+
+// logging assigns the request ID, logs the processed request with its status, response size and matched
+// route pattern, and records request metrics. The request ID is taken from the X-Request-Id request header
+// if it is valid, generated otherwise, and returned in the X-Request-Id response header.
 func (s *Server) logging(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		defer func(start time.Time) {
-			requestID := w.Header().Get("X-Request-Id")
-			if requestID == "" {
-				requestID = "unknown"
-			}
+		requestID := req.Header.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
 
+		w.Header().Set(requestIDHeader, requestID)
+		req = req.WithContext(context.WithValue(req.Context(), requestIDCtxKey{}, requestID))
+		route := s.route(req)
+		sw := &statusWriter{ResponseWriter: w}
+		defer func(start time.Time) {
+			duration := time.Since(start)
+			s.metrics.observe(route, req.Method, sw.status(), duration)
 			s.log.Info("processed request", wlog.String("request_id", requestID), wlog.String("method", req.Method),
-				wlog.String("path", req.URL.Path), wlog.String("remote", req.RemoteAddr), wlog.String("ua", req.UserAgent()),
-				wlog.Any("duration", time.Since(start)))
+				wlog.String("path", req.URL.Path), wlog.String("route", route), wlog.Int("status", sw.status()),
+				wlog.Int64("bytes", sw.bytes), wlog.String("remote", req.RemoteAddr), wlog.String("ua", req.UserAgent()),
+				wlog.Any("duration", duration))
 		}(time.Now())
 
-		h.ServeHTTP(w, req)
+		h.ServeHTTP(sw, req)
 	})
 }
 
+// route returns the pattern of the route matching the request, or "unmatched".
+func (s *Server) route(req *http.Request) string {
+	_, pattern := s.public.Handler(req)
+	if pattern == "/" {
+		_, pattern = s.router.Handler(req)
+	}
+
+	if pattern == "" {
+		return "unmatched"
+	}
+
+	return pattern
+}
+
 func (s *Server) recoverPanic(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -40,21 +86,161 @@ func (s *Server) recoverPanic(h http.Handler) http.Handler {
 	})
 }
 
+// authenticate verifies the access token passed in the `Authorization: Bearer` header or the `access_token`
+// query parameter. The token is either the master token from the configuration, which grants access
+// to all domains, or one of the API tokens stored hashed in the database, which may be scoped to domains.
+// Requests to the domain the token is not scoped to are rejected with 403.
+// Attachment downloads may be authorized by a signed URL instead of the token,
+// and any request by a client certificate verified against the configured client CA,
+// which is scoped to the domains configured for its common name.
 func (s *Server) authenticate(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token := r.URL.Query().Get("access_token")
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			t, ok := s.certToken(r.TLS.VerifiedChains[0][0].Subject.CommonName)
+			if !ok {
+				JSON(w, Error{Msg: "client certificate is not allowed"}, http.StatusForbidden)
+
+				return
+			}
+
+			s.serveToken(w, r, h, t)
+
+			return
+		}
+
+		if r.URL.Query().Has(auth.SignatureParam) {
+			if !s.verifySignature(r) {
+				JSON(w, Error{Msg: "signature is invalid or expired"}, http.StatusForbidden)
+
+				return
+			}
+
+			h.ServeHTTP(w, r)
+
+			return
+		}
+
+		token := bearerToken(r)
 		if len(token) == 0 {
 			JSON(w, Error{Msg: "access token is missing"}, http.StatusUnauthorized)
 
 			return
 		}
 
-		if token != s.cfg.Server.Token {
-			JSON(w, Error{Msg: "unauthorized"}, http.StatusUnauthorized)
+		t, err := s.lookupToken(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, db.ErrDBNoExists) {
+				JSON(w, Error{Msg: "unauthorized"}, http.StatusUnauthorized)
+
+				return
+			}
+
+			JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
 
 			return
 		}
 
-		h.ServeHTTP(w, r)
+		s.serveToken(w, r, h, t)
 	})
 }
+
+// serveToken serves the request authenticated with the token, unless the token isn't scoped
+// to the domain of the request path.
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request, h http.Handler, t *models.Token) {
+	if domain, ok := pathDomain(r.URL.Path); ok && !t.Allows(domain) {
+		JSON(w, Error{Msg: "forbidden"}, http.StatusForbidden)
+
+		return
+	}
+
+	h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenCtxKey{}, t)))
+}
+
+// certToken returns the token of the verified client certificate with the given common name, scoped to
+// the domains configured for it. Certificates without configured domains are allowed only if access
+// to all domains is granted to them.
+func (s *Server) certToken(commonName string) (*models.Token, bool) {
+	domains, ok := s.cfg.Server.TLSClientDomains[commonName]
+	if !ok && !s.cfg.Server.TLSClientAllDomains {
+		return nil, false
+	}
+
+	return &models.Token{Name: clientCertTokenPrefix + commonName, DomainIDs: domains}, true
+}
+
+// verifySignature reports whether the request is an attachment download with a valid signature
+// of exactly this domain, ticket and attachment.
+func (s *Server) verifySignature(r *http.Request) bool {
+	if s.signer == nil || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+
+	match := attachmentPathRegexp.FindStringSubmatch(r.URL.Path)
+	if match == nil {
+		return false
+	}
+
+	var ids [3]int64
+	for i := range ids {
+		id, err := parseInt(match[i+1])
+		if err != nil {
+			return false
+		}
+
+		ids[i] = id
+	}
+
+	return s.signer.Verify(ids[0], ids[1], ids[2], r.URL.Query(), time.Now())
+}
+
+// lookupToken returns the token matching the master token or one of the active API tokens.
+func (s *Server) lookupToken(ctx context.Context, token string) (*models.Token, error) {
+	if s.cfg.Server.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Server.Token)) == 1 {
+		return &models.Token{Name: masterTokenName}, nil
+	}
+
+	if s.dbpool == nil {
+		return nil, db.ErrDBNoExists
+	}
+
+	return s.dbpool.TokenByHash(ctx, auth.HashToken(token))
+}
+
+// bearerToken returns the token from the Authorization header or, if it is not set, from the access_token query parameter.
+func bearerToken(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		scheme, token, ok := strings.Cut(h, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+
+		return ""
+	}
+
+	return r.URL.Query().Get("access_token")
+}
+
+// pathDomain returns the domain ID from the first segment of the request path: all domain routes start with /{domain_id}/.
+func pathDomain(path string) (int64, bool) {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	domain, err := parseInt(segment)
+	if err != nil {
+		return 0, false
+	}
+
+	return domain, true
+}
+
+// RequestID returns the ID of the request.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+
+	return id
+}
+
+// RequestToken returns the token the request was authenticated with.
+func RequestToken(ctx context.Context) (*models.Token, bool) {
+	t, ok := ctx.Value(tokenCtxKey{}).(*models.Token)
+
+	return t, ok
+}
@@ -0,0 +1,115 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/auth"
+)
+
+// newTestServer returns the server without the database and the handler responding with 200
+// to requests passed by the authenticate middleware.
+func newTestServer(configure func(cfg *config.Config)) (*Server, http.Handler) {
+	cfg := config.New()
+	cfg.Server.SigningKey = "test-signing-key"
+	if configure != nil {
+		configure(cfg)
+	}
+
+	s := New(cfg, wlog.NewLogger(&wlog.LoggerConfiguration{EnableConsole: true, ConsoleLevel: wlog.LevelError}))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return s, s.authenticate(ok)
+}
+
+func TestAuthenticateSignedURL(t *testing.T) {
+	_, h := newTestServer(nil)
+	signer := auth.NewSigner("test-signing-key")
+	valid := signer.Sign(1, 2, 3, time.Now().Add(time.Hour)).Encode()
+	for _, tt := range []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{name: "valid", method: http.MethodGet, target: "/1/ticket/2/attachments/3?" + valid, want: http.StatusOK},
+		{name: "head", method: http.MethodHead, target: "/1/ticket/2/attachments/3?" + valid, want: http.StatusOK},
+		{name: "other domain", method: http.MethodGet, target: "/4/ticket/2/attachments/3?" + valid,
+			want: http.StatusForbidden},
+		{name: "other ticket", method: http.MethodGet, target: "/1/ticket/4/attachments/3?" + valid,
+			want: http.StatusForbidden},
+		{name: "other attachment", method: http.MethodGet, target: "/1/ticket/2/attachments/4?" + valid,
+			want: http.StatusForbidden},
+		{name: "other route", method: http.MethodGet, target: "/1/ticket/2?" + valid, want: http.StatusForbidden},
+		{name: "wrong method", method: http.MethodDelete, target: "/1/ticket/2/attachments/3?" + valid,
+			want: http.StatusForbidden},
+		{name: "expired", method: http.MethodGet, want: http.StatusForbidden,
+			target: "/1/ticket/2/attachments/3?" + signer.Sign(1, 2, 3, time.Now().Add(-time.Minute)).Encode()},
+		{name: "other key", method: http.MethodGet, want: http.StatusForbidden,
+			target: "/1/ticket/2/attachments/3?" + auth.NewSigner("other").Sign(1, 2, 3, time.Now().Add(time.Hour)).Encode()},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestAuthenticateScopedToken(t *testing.T) {
+	s, h := newTestServer(func(cfg *config.Config) {
+		cfg.Server.TLSClientDomains = map[string][]int64{"reports": {1, 2}}
+	})
+
+	for _, tt := range []struct {
+		name       string
+		commonName string
+		path       string
+		want       int
+	}{
+		{name: "scoped domain", commonName: "reports", path: "/2/tickets", want: http.StatusOK},
+		{name: "other domain", commonName: "reports", path: "/3/tickets", want: http.StatusForbidden},
+		{name: "other domain attachment", commonName: "reports", path: "/3/ticket/1/attachments/1",
+			want: http.StatusForbidden},
+		{name: "not domain route", commonName: "reports", path: "/ping", want: http.StatusOK},
+		{name: "unknown certificate", commonName: "other", path: "/1/tickets", want: http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.TLS = &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: tt.commonName}}}},
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+
+	// API tokens looked up in the database are served the same way
+	token := &models.Token{ID: 1, Name: "grafana", DomainIDs: []int64{1}}
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got, ok := RequestToken(req.Context()); !ok || got != token {
+			t.Errorf("got request token %v, want %v", got, token)
+		}
+	})
+
+	for path, want := range map[string]int{"/1/tickets": http.StatusOK, "/2/tickets": http.StatusForbidden} {
+		w := httptest.NewRecorder()
+		s.serveToken(w, httptest.NewRequest(http.MethodGet, path, nil), next, token)
+		if w.Code != want {
+			t.Errorf("API token %s: got status %d, want %d", path, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package httpserver
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPI is the OpenAPI 3 document describing the API. Keep it in sync with the routes in RegisterHandlers.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+// OpenAPI responds with the OpenAPI document.
+func OpenAPI(w http.ResponseWriter, req *http.Request) {
+	headers := http.Header{}
+	headers.Add("Content-Type", "application/json")
+	File(w, openAPI, http.StatusOK, headers)
+}
@@ -0,0 +1,218 @@
+package httpserver
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// clientIdleTimeout is the time after which limiters of an inactive client are dropped.
+const clientIdleTimeout = 10 * time.Minute
+
+// downloadPathRegexp matches routes streaming files: ticket and article attachments and ticket bundles.
+var downloadPathRegexp = regexp.MustCompile(`^/\d+/(ticket/\d+/(attachments/\d+|bundle\.zip)|solutions/articles/\d+/attachments/\d+)$`)
+
+// client holds the limiters of a single API client.
+type client struct {
+	requests  *rate.Limiter
+	bandwidth *rate.Limiter
+	seenAt    time.Time
+}
+
+// limiter limits request rate and download bandwidth per client, and the number of concurrent downloads
+// across all clients. A client is identified by the token the request was authenticated with or,
+// for requests without a token (signed URLs), by the remote IP address. The limiter of IP addresses
+// before authentication limits only the request rate.
+type limiter struct {
+	rate      rate.Limit
+	burst     int
+	bandwidth int64
+	downloads chan struct{}
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	cleanedAt time.Time
+}
+
+func newLimiter(rps float64, burst, maxDownloads int, bandwidth int64) *limiter {
+	l := &limiter{
+		rate:      rate.Limit(rps),
+		burst:     burst,
+		bandwidth: bandwidth,
+		clients:   make(map[string]*client),
+		cleanedAt: time.Now(),
+	}
+
+	if rps <= 0 {
+		l.rate = rate.Inf
+	}
+
+	if l.burst < 1 {
+		l.burst = 1
+	}
+
+	if maxDownloads > 0 {
+		l.downloads = make(chan struct{}, maxDownloads)
+	}
+
+	return l
+}
+
+// client returns limiters of the client, creating them on the first request.
+func (l *limiter) client(key string) *client {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.cleanedAt) > clientIdleTimeout {
+		for k, c := range l.clients {
+			if now.Sub(c.seenAt) > clientIdleTimeout {
+				delete(l.clients, k)
+			}
+		}
+
+		l.cleanedAt = now
+	}
+
+	c, ok := l.clients[key]
+	if !ok {
+		c = &client{requests: rate.NewLimiter(l.rate, l.burst)}
+		if l.bandwidth > 0 {
+			c.bandwidth = rate.NewLimiter(rate.Limit(l.bandwidth), int(l.bandwidth))
+		}
+
+		l.clients[key] = c
+	}
+
+	c.seenAt = now
+
+	return c
+}
+
+// limitIP rejects requests exceeding the request rate of the remote IP address with 429 and the Retry-After header.
+// It runs before authenticate, so requests with invalid credentials are limited too.
+func (s *Server) limitIP(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := s.ipLimiter.client("ip:" + remoteIP(r))
+		if res := c.requests.Reserve(); !res.OK() || res.Delay() > 0 {
+			delay := res.Delay()
+			res.Cancel()
+			tooManyRequests(w, delay, "IP address rate limit exceeded")
+
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// limit rejects requests exceeding the client request rate or the concurrent downloads cap with 429
+// and the Retry-After header, and throttles download responses to the client bandwidth.
+// It must run after authenticate to identify clients by token.
+func (s *Server) limit(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := s.limiter.client(clientKey(r))
+		if res := c.requests.Reserve(); !res.OK() || res.Delay() > 0 {
+			delay := res.Delay()
+			res.Cancel()
+			tooManyRequests(w, delay, "request rate limit exceeded")
+
+			return
+		}
+
+		if !downloadPathRegexp.MatchString(r.URL.Path) {
+			h.ServeHTTP(w, r)
+
+			return
+		}
+
+		if s.limiter.downloads != nil {
+			select {
+			case s.limiter.downloads <- struct{}{}:
+				defer func() { <-s.limiter.downloads }()
+			default:
+				tooManyRequests(w, time.Second, "too many concurrent downloads")
+
+				return
+			}
+		}
+
+		if c.bandwidth != nil {
+			w = &throttledWriter{ResponseWriter: w, ctx: r.Context(), limiter: c.bandwidth}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// clientKey returns the ID of the request token or, if there is none, the remote IP address.
+// The master token and client certificates have no ID and are identified by their names,
+// which API tokens can't collide with.
+func clientKey(r *http.Request) string {
+	if t, ok := RequestToken(r.Context()); ok {
+		if t.ID != 0 {
+			return "token:" + strconv.FormatInt(t.ID, 10)
+		}
+
+		return "name:" + t.Name
+	}
+
+	return "ip:" + remoteIP(r)
+}
+
+// remoteIP returns the IP address of the remote address of the request.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration, msg string) {
+	if retryAfter <= 0 || retryAfter == rate.InfDuration {
+		retryAfter = time.Second
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	JSON(w, Error{Msg: msg}, http.StatusTooManyRequests)
+}
+
+// throttledWriter writes the response body no faster than the limiter allows.
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (t *throttledWriter) Write(b []byte) (int, error) {
+	var written int
+	for len(b) > 0 {
+		n := min(len(b), t.limiter.Burst())
+		if err := t.limiter.WaitN(t.ctx, n); err != nil {
+			return written, err
+		}
+
+		n, err := t.ResponseWriter.Write(b[:n])
+		written += n
+		if err != nil {
+			return written, err
+		}
+
+		b = b[n:]
+	}
+
+	return written, nil
+}
+
+func (t *throttledWriter) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
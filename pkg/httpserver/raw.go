@@ -0,0 +1,77 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kirychukyurii/fd-import/pkg/db"
+)
+
+// RawTicket responds with the original Freshdesk ticket JSON exactly as imported. The optional `pointer`
+// query parameter is a JSON pointer (RFC 6901), e.g. /custom_fields, selecting a nested value of the document,
+// which is extracted from the jsonb storage, so keys order and whitespace of the value are normalized.
+func (t *Ticket) RawTicket(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	ticketID, err := parseInt(req.PathValue("ticket_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "ticket id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	path, err := parsePointer(req.URL.Query().Get("pointer"))
+	if err != nil {
+		JSON(w, Error{Msg: err.Error()}, http.StatusBadRequest)
+
+		return
+	}
+
+	raw, err := t.dbpool.RawTicket(req.Context(), domainID, ticketID, path)
+	if err != nil {
+		if errors.Is(err, db.ErrDBNoExists) {
+			JSON(w, Error{Msg: "ticket not found"}, http.StatusNotFound)
+
+			return
+		}
+
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	if raw == nil {
+		JSON(w, Error{Msg: "pointer not found"}, http.StatusNotFound)
+
+		return
+	}
+
+	headers := http.Header{}
+	headers.Add("Content-Type", "application/json")
+	File(w, raw, http.StatusOK, headers)
+}
+
+// parsePointer splits the JSON pointer into unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("pointer is invalid, it must start with /")
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,75 @@
+package httpserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	requestIDHeader    = "X-Request-Id"
+	maxRequestIDLength = 128
+)
+
+// newRequestID returns a random 128-bit request ID in hex.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// validRequestID reports whether the request ID passed by the client can be used:
+// it must be non-empty, not too long and contain printable ASCII characters only.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statusWriter records the status code and the number of bytes written to the response.
+type statusWriter struct {
+	http.ResponseWriter
+	code  int
+	bytes int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+
+	return n, err
+}
+
+// status returns the response status code, 200 if nothing was written.
+func (w *statusWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+
+	return w.code
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,69 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchList represents a page of the full-text search results.
+type SearchList struct {
+	Items []*models.SearchResult `json:"items"`
+}
+
+// Search responds with tickets matching the full-text query ranked by relevance. Query parameters:
+//   - q: search query in web search syntax, e.g. `refund "order 123" -spam`;
+//   - limit: page size;
+//   - offset: number of results to skip.
+func (t *Ticket) Search(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	q := req.URL.Query()
+	query := q.Get("q")
+	if query == "" {
+		JSON(w, Error{Msg: "query is missing"}, http.StatusBadRequest)
+
+		return
+	}
+
+	limit := uint64(defaultSearchLimit)
+	if s := q.Get("limit"); s != "" {
+		limit, err = strconv.ParseUint(s, 10, 64)
+		if err != nil || limit == 0 || limit > maxSearchLimit {
+			JSON(w, Error{Msg: fmt.Sprintf("limit is invalid, use a value from 1 to %d", maxSearchLimit)}, http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	var offset uint64
+	if s := q.Get("offset"); s != "" {
+		offset, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			JSON(w, Error{Msg: "offset is invalid"}, http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	results, err := t.dbpool.Search(req.Context(), domainID, query, limit, offset)
+	if err != nil {
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	JSON(w, SearchList{Items: results}, http.StatusOK)
+}
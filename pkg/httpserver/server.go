@@ -2,47 +2,89 @@ package httpserver
 
 import (
 	"context"
+	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/webitel/wlog"
 
 	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/pkg/auth"
+	"github.com/kirychukyurii/fd-import/pkg/db"
 )
 
 type Server struct {
 	cfg *config.Config
 	log *wlog.Logger
 
-	srv    *http.Server
-	router *http.ServeMux
+	srv       *http.Server
+	public    *http.ServeMux
+	router    *http.ServeMux
+	dbpool    *db.Connection
+	signer    *auth.Signer
+	limiter   *limiter
+	ipLimiter *limiter
+	metrics   *metrics
 }
 
 func New(cfg *config.Config, log *wlog.Logger) *Server {
 	mux := http.NewServeMux()
+	public := http.NewServeMux()
 	srv := &http.Server{
 		Addr:     cfg.Server.Address,
 		ErrorLog: log.StdLog(),
 	}
 
 	s := &Server{
-		cfg:    cfg,
-		log:    log,
-		srv:    srv,
-		router: mux,
+		cfg:     cfg,
+		log:     log,
+		srv:     srv,
+		public:  public,
+		router:  mux,
+		metrics: newMetrics(),
 	}
 
-	s.srv.Handler = s.recoverPanic(s.logging(s.authenticate(s.router)))
+	if cfg.Server.SigningKey != "" {
+		s.signer = auth.NewSigner(cfg.Server.SigningKey)
+	}
+
+	// Routes of the public mux, e.g. health probes, are served without authentication,
+	// everything else falls through to the authenticated router.
+	// Requests are limited per IP address before authentication, so invalid credentials can't be brute-forced,
+	// and per token after it.
+	s.limiter = newLimiter(cfg.Server.RateLimit, cfg.Server.RateBurst, cfg.Server.MaxDownloads, cfg.Server.DownloadBandwidth)
+	s.ipLimiter = newLimiter(cfg.Server.IPRateLimit, cfg.Server.IPRateBurst, 0, 0)
+	s.public.Handle("/", s.limitIP(s.authenticate(s.limit(s.router))))
+	s.srv.Handler = s.recoverPanic(s.logging(s.public))
 
 	return s
 }
 
+// Handler returns the HTTP handler of the server with all middlewares, e.g. to run it in-process.
+func (s *Server) Handler() http.Handler {
+	return s.srv.Handler
+}
+
+// Serve listens on the configured address and serves requests over HTTPS (HTTP/2 or HTTP/1.1)
+// if the TLS certificate is configured, or over plain HTTP otherwise.
 func (s *Server) Serve() error {
-	return s.srv.ListenAndServe()
+	if s.cfg.Server.TLSCert == "" && s.cfg.Server.TLSKey == "" {
+		return s.srv.ListenAndServe()
+	}
+
+	c, err := s.tlsConfig()
+	if err != nil {
+		return fmt.Errorf("tls config: %v", err)
+	}
+
+	s.srv.TLSConfig = c
+
+	return s.srv.ListenAndServeTLS("", "")
 }
 
+// Stop stops accepting new connections and waits for in-flight requests to complete
+// during the configured shutdown timeout.
 func (s *Server) Stop() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := s.srv.Shutdown(ctx); err != nil {
@@ -0,0 +1,233 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+)
+
+const (
+	defaultArticlesLimit = 50
+	maxArticlesLimit     = 500
+)
+
+// CategoryList represents solution categories of the domain with their folders.
+type CategoryList struct {
+	Items []*models.SolutionCategory `json:"items"`
+}
+
+// ArticleList represents a page of the solution article list.
+type ArticleList struct {
+	Items []*models.SolutionArticle `json:"items"`
+}
+
+// ArticleSearchList represents a page of the solution article search results.
+type ArticleSearchList struct {
+	Items []*models.ArticleSearchResult `json:"items"`
+}
+
+type Solution struct {
+	cfg    *config.Config
+	log    *wlog.Logger
+	dbpool *db.Connection
+}
+
+func NewSolutionHandler(cfg *config.Config, log *wlog.Logger, dbpool *db.Connection) *Solution {
+	return &Solution{
+		cfg:    cfg,
+		log:    log,
+		dbpool: dbpool,
+	}
+}
+
+// Categories responds with the knowledge base tree: solution categories with their folders.
+func (s *Solution) Categories(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	categories, err := s.dbpool.SolutionCategories(req.Context(), domainID)
+	if err != nil {
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	JSON(w, CategoryList{Items: categories}, http.StatusOK)
+}
+
+// Articles responds with a page of solution article summaries without bodies. Query parameters:
+//   - category_id, folder_id: the category or folder of articles;
+//   - status: 1 (draft) or 2 (published);
+//   - tags: comma-separated list of tags, the article must have all of them;
+//   - limit: page size;
+//   - offset: number of articles to skip.
+func (s *Solution) Articles(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	filter, err := parseArticleFilter(req.URL.Query())
+	if err != nil {
+		JSON(w, Error{Msg: err.Error()}, http.StatusBadRequest)
+
+		return
+	}
+
+	filter.DomainID = domainID
+	articles, err := s.dbpool.SolutionArticles(req.Context(), filter)
+	if err != nil {
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	JSON(w, ArticleList{Items: articles}, http.StatusOK)
+}
+
+// Article responds with the solution article, its HTML body and attachment metadata with download URLs.
+func (s *Solution) Article(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	articleID, err := parseInt(req.PathValue("article_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "article id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	article, err := s.dbpool.SolutionArticle(req.Context(), domainID, articleID)
+	if err != nil {
+		if errors.Is(err, db.ErrDBNoExists) {
+			JSON(w, Error{Msg: "article not found"}, http.StatusNotFound)
+
+			return
+		}
+
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	for _, a := range article.Attachments {
+		a.DownloadURL = ArticleAttachmentPath(domainID, articleID, a.ID)
+	}
+
+	JSON(w, article, http.StatusOK)
+}
+
+// Search responds with solution articles matching the full-text query ranked by relevance.
+// Query parameters are the same as of the ticket search: q, limit and offset.
+func (s *Solution) Search(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	q := req.URL.Query()
+	query := q.Get("q")
+	if query == "" {
+		JSON(w, Error{Msg: "query is missing"}, http.StatusBadRequest)
+
+		return
+	}
+
+	limit := uint64(defaultSearchLimit)
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.ParseUint(v, 10, 64)
+		if err != nil || limit == 0 || limit > maxSearchLimit {
+			JSON(w, Error{Msg: fmt.Sprintf("limit is invalid, use a value from 1 to %d", maxSearchLimit)}, http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	var offset uint64
+	if v := q.Get("offset"); v != "" {
+		offset, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			JSON(w, Error{Msg: "offset is invalid"}, http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	results, err := s.dbpool.SearchArticles(req.Context(), domainID, query, limit, offset)
+	if err != nil {
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	JSON(w, ArticleSearchList{Items: results}, http.StatusOK)
+}
+
+// ArticleAttachmentPath returns the path of the solution article attachment download endpoint.
+func ArticleAttachmentPath(domainID, articleID, id int64) string {
+	return fmt.Sprintf("/%d/solutions/articles/%d/attachments/%d", domainID, articleID, id)
+}
+
+func parseArticleFilter(q url.Values) (*db.ArticleFilter, error) {
+	var (
+		f   = &db.ArticleFilter{Limit: defaultArticlesLimit}
+		err error
+	)
+
+	if v := q.Get("category_id"); v != "" {
+		if f.CategoryID, err = parseInt(v); err != nil {
+			return nil, fmt.Errorf("category_id is invalid")
+		}
+	}
+
+	if v := q.Get("folder_id"); v != "" {
+		if f.FolderID, err = parseInt(v); err != nil {
+			return nil, fmt.Errorf("folder_id is invalid")
+		}
+	}
+
+	switch v := q.Get("status"); v {
+	case "":
+	case strconv.Itoa(models.ArticleStatusDraft), strconv.Itoa(models.ArticleStatusPublished):
+		f.Status, _ = parseInt(v)
+	default:
+		return nil, fmt.Errorf("status is invalid, use %d (draft) or %d (published)", models.ArticleStatusDraft,
+			models.ArticleStatusPublished)
+	}
+
+	f.Tags = parseList(q.Get("tags"))
+	if v := q.Get("limit"); v != "" {
+		f.Limit, err = strconv.ParseUint(v, 10, 64)
+		if err != nil || f.Limit == 0 || f.Limit > maxArticlesLimit {
+			return nil, fmt.Errorf("limit is invalid, use a value from 1 to %d", maxArticlesLimit)
+		}
+	}
+
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("offset is invalid")
+		}
+	}
+
+	return f, nil
+}
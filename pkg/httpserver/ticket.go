@@ -0,0 +1,287 @@
+package httpserver
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/webitel/wlog"
+
+	"github.com/kirychukyurii/fd-import/config"
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/auth"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+)
+
+const (
+	defaultTicketsLimit = 50
+	maxTicketsLimit     = 500
+)
+
+// TicketList represents a page of the ticket list.
+// Next is the cursor of the following page, it is empty on the last page.
+type TicketList struct {
+	Items []*models.Ticket `json:"items"`
+	Next  string           `json:"next,omitempty"`
+}
+
+type Ticket struct {
+	cfg    *config.Config
+	log    *wlog.Logger
+	dbpool *db.Connection
+	signer *auth.Signer
+}
+
+func NewTicketHandler(cfg *config.Config, log *wlog.Logger, dbpool *db.Connection, signer *auth.Signer) *Ticket {
+	return &Ticket{
+		cfg:    cfg,
+		log:    log,
+		dbpool: dbpool,
+		signer: signer,
+	}
+}
+
+// Tickets responds with a page of ticket summaries. Query parameters:
+//   - status, priority, source: comma-separated lists of values;
+//   - requester_name: exact requester name;
+//   - tags: comma-separated list of tags, the ticket must have all of them;
+//   - created_from, created_to: creation time window, RFC3339 or YYYY-MM-DD;
+//   - q: substring of the ticket subject;
+//   - sort: created_at (ascending) or -created_at (descending, default);
+//   - limit: page size;
+//   - cursor: value of the next field from the previous page.
+func (t *Ticket) Tickets(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	filter, err := parseTicketFilter(req.URL.Query())
+	if err != nil {
+		JSON(w, Error{Msg: err.Error()}, http.StatusBadRequest)
+
+		return
+	}
+
+	filter.DomainID = domainID
+	tickets, err := t.dbpool.Tickets(req.Context(), filter)
+	if err != nil {
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	list := TicketList{Items: tickets}
+	if n := len(tickets); n > 0 && uint64(n) == filter.Limit {
+		list.Next = encodeCursor(&db.TicketCursor{CreatedAt: tickets[n-1].CreatedAt, ID: tickets[n-1].ID})
+	}
+
+	JSON(w, list, http.StatusOK)
+}
+
+// Ticket responds with the ticket, its conversations and attachment metadata with download URLs.
+func (t *Ticket) Ticket(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	ticketID, err := parseInt(req.PathValue("ticket_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "ticket id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	ticket, err := t.dbpool.TicketDetails(req.Context(), domainID, ticketID)
+	if err != nil {
+		if errors.Is(err, db.ErrDBNoExists) {
+			JSON(w, Error{Msg: "ticket not found"}, http.StatusNotFound)
+
+			return
+		}
+
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	t.setDownloadURLs(ticket.Attachments, domainID, ticketID)
+	for _, cc := range ticket.Conversations {
+		t.setDownloadURLs(cc.Attachments, domainID, ticketID)
+	}
+
+	JSON(w, ticket, http.StatusOK)
+}
+
+// setDownloadURLs sets download URLs of the attachment endpoint to the ticket attachments.
+func (t *Ticket) setDownloadURLs(attachments []*models.Attachment, domainID, ticketID int64) {
+	for _, a := range attachments {
+		a.DownloadURL = t.attachmentURL(domainID, ticketID, a.ID)
+	}
+}
+
+// attachmentURL returns the path of the attachment download endpoint. The path is signed
+// if signed URLs are enabled, so it can be used without the access token until it expires.
+func (t *Ticket) attachmentURL(domainID, ticketID, id int64) string {
+	u := AttachmentPath(domainID, ticketID, id)
+	if t.signer == nil {
+		return u
+	}
+
+	return u + "?" + t.signer.Sign(domainID, ticketID, id, time.Now().Add(t.cfg.Server.SignedURLTTL)).Encode()
+}
+
+// AttachmentPath returns the path of the attachment download endpoint.
+func AttachmentPath(domainID, ticketID, id int64) string {
+	return fmt.Sprintf("/%d/ticket/%d/attachments/%d", domainID, ticketID, id)
+}
+
+func parseTicketFilter(q url.Values) (*db.TicketFilter, error) {
+	var (
+		filter = &db.TicketFilter{
+			RequesterName: q.Get("requester_name"),
+			Subject:       q.Get("q"),
+			Tags:          parseList(q.Get("tags")),
+			Limit:         defaultTicketsLimit,
+		}
+		err error
+	)
+
+	if filter.Statuses, err = parseIntList(q.Get("status")); err != nil {
+		return nil, fmt.Errorf("status is invalid")
+	}
+
+	if filter.Priorities, err = parseIntList(q.Get("priority")); err != nil {
+		return nil, fmt.Errorf("priority is invalid")
+	}
+
+	if filter.Sources, err = parseIntList(q.Get("source")); err != nil {
+		return nil, fmt.Errorf("source is invalid")
+	}
+
+	if filter.CreatedFrom, err = parseTime(q.Get("created_from")); err != nil {
+		return nil, fmt.Errorf("created_from is invalid")
+	}
+
+	if filter.CreatedTo, err = parseTime(q.Get("created_to")); err != nil {
+		return nil, fmt.Errorf("created_to is invalid")
+	}
+
+	switch q.Get("sort") {
+	case "", "-created_at":
+	case "created_at":
+		filter.Asc = true
+	default:
+		return nil, fmt.Errorf("sort is invalid, use created_at or -created_at")
+	}
+
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || limit == 0 || limit > maxTicketsLimit {
+			return nil, fmt.Errorf("limit is invalid, use a value from 1 to %d", maxTicketsLimit)
+		}
+
+		filter.Limit = limit
+	}
+
+	if s := q.Get("cursor"); s != "" {
+		if filter.After, err = decodeCursor(s); err != nil {
+			return nil, fmt.Errorf("cursor is invalid")
+		}
+	}
+
+	return filter, nil
+}
+
+// encodeCursor encodes the ticket list position into an opaque string.
+// The creation time is empty for tickets without it.
+func encodeCursor(c *db.TicketCursor) string {
+	var ts string
+	if c.CreatedAt != nil {
+		ts = c.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	s := fmt.Sprintf("%s,%d", ts, c.ID)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func decodeCursor(s string) (*db.TicketCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, id, ok := strings.Cut(string(b), ",")
+	if !ok {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	ticketID, err := parseInt(id)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &db.TicketCursor{ID: ticketID}
+	if ts != "" {
+		createdAt, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return nil, err
+		}
+
+		c.CreatedAt = &createdAt
+	}
+
+	return c, nil
+}
+
+// parseList splits comma-separated values, skipping empty ones.
+func parseList(s string) []string {
+	list := make([]string, 0)
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+func parseIntList(s string) ([]int64, error) {
+	list := make([]int64, 0)
+	for _, v := range parseList(s) {
+		i, err := parseInt(v)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, i)
+	}
+
+	return list, nil
+}
+
+// parseTime parses the time in RFC3339 or YYYY-MM-DD format. It returns nil for an empty string.
+func parseTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported time format %q", s)
+}
@@ -0,0 +1,131 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/webitel/wlog"
+)
+
+// certReloadInterval is the minimal interval between checks of the certificate files for changes.
+const certReloadInterval = 10 * time.Second
+
+// certReloader serves the TLS certificate and reloads it when the certificate or key file is modified,
+// so renewed certificates are picked up without a restart. If reloading fails, the previous certificate is kept.
+type certReloader struct {
+	log      *wlog.Logger
+	certFile string
+	keyFile  string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(log *wlog.Logger, certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{
+		log:      log,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := time.Now(); now.Sub(r.checkedAt) >= certReloadInterval {
+		r.checkedAt = now
+		modTime, err := r.latestModTime()
+		if err != nil {
+			r.log.Warn("check tls certificate", wlog.Err(err))
+		} else if !modTime.Equal(r.modTime) {
+			if err := r.load(modTime); err != nil {
+				r.log.Warn("reload tls certificate", wlog.Err(err))
+			} else {
+				r.log.Info("reloaded tls certificate", wlog.String("cert", r.certFile))
+			}
+		}
+	}
+
+	return r.cert, nil
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %v", err)
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+	r.checkedAt = time.Now()
+
+	return nil
+}
+
+// latestModTime returns the latest modification time of the certificate and key files.
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+// tlsConfig returns the TLS configuration of the server. If the client CA file is set,
+// client certificates are requested and verified against it, and a verified certificate
+// authenticates the request instead of the access token.
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	reloader, err := newCertReloader(s.log, s.cfg.Server.TLSCert, s.cfg.Server.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if s.cfg.Server.TLSClientCA != "" {
+		pem, err := os.ReadFile(s.cfg.Server.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("read client ca: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("client ca %s contains no certificates", s.cfg.Server.TLSClientCA)
+		}
+
+		c.ClientCAs = pool
+		c.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return c, nil
+}
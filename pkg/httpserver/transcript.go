@@ -0,0 +1,60 @@
+package httpserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/db"
+	"github.com/kirychukyurii/fd-import/pkg/transcript"
+)
+
+// Transcript responds with the printable HTML document of the ticket and its conversations.
+// Attachments are linked to the attachment download endpoint.
+func (t *Ticket) Transcript(w http.ResponseWriter, req *http.Request) {
+	domainID, err := parseInt(req.PathValue("domain_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "domain id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	ticketID, err := parseInt(req.PathValue("ticket_id"))
+	if err != nil {
+		JSON(w, Error{Msg: "ticket id is invalid"}, http.StatusBadRequest)
+
+		return
+	}
+
+	ticket, err := t.dbpool.TicketDetails(req.Context(), domainID, ticketID)
+	if err != nil {
+		if errors.Is(err, db.ErrDBNoExists) {
+			JSON(w, Error{Msg: "ticket not found"}, http.StatusNotFound)
+
+			return
+		}
+
+		JSON(w, Error{Msg: fmt.Sprintf("database: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	opts := &transcript.Options{
+		AttachmentURL: func(a *models.Attachment) string {
+			return t.attachmentURL(domainID, ticketID, a.ID)
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := transcript.Render(&buf, ticket, opts); err != nil {
+		JSON(w, Error{Msg: fmt.Sprintf("render: %s", err)}, http.StatusInternalServerError)
+
+		return
+	}
+
+	headers := http.Header{}
+	headers.Add("Content-Type", "text/html; charset=utf-8")
+	File(w, buf.Bytes(), http.StatusOK, headers)
+}
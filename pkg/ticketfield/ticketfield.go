@@ -0,0 +1,222 @@
+// Package ticketfield decodes custom fields of tickets into labelled, typed values
+// using the imported ticket field definitions.
+package ticketfield
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+// Freshdesk types of custom fields converted by the resolver, values of other types are returned as is.
+const (
+	TypeCheckbox = "custom_checkbox"
+	TypeNumber   = "custom_number"
+	TypeDecimal  = "custom_decimal"
+	TypeDate     = "custom_date"
+	TypeDropdown = "custom_dropdown"
+	TypeNested   = "nested_field"
+)
+
+// field is the definition of a custom field, or of a level of the nested dropdown.
+type field struct {
+	label    string
+	typ      string
+	position int64
+	level    int64
+
+	// choices maps choice IDs to their values, for exports storing the choice ID instead of the value.
+	choices map[string]string
+}
+
+// Resolver decodes custom fields of tickets of a domain.
+type Resolver struct {
+	fields map[string]*field
+}
+
+// New returns the resolver of the given field definitions. Custom fields without a definition
+// are resolved too, labelled by their names.
+func New(fields []*models.TicketField) *Resolver {
+	r := &Resolver{fields: make(map[string]*field, len(fields))}
+	for _, f := range fields {
+		r.fields[f.Name] = &field{label: f.Label, typ: f.Type, position: f.Position, level: 1, choices: choiceIDs(f.Choices)}
+		for _, n := range f.NestedTicketFields {
+			r.fields[n.Name] = &field{label: n.Label, typ: f.Type, position: f.Position, level: n.Level}
+		}
+	}
+
+	return r
+}
+
+// Resolve returns non-null custom fields of the ticket ordered as in the ticket form:
+// defined fields by position and nesting level, undefined ones by name after them.
+func (r *Resolver) Resolve(customFields any) []*models.CustomFieldValue {
+	m, ok := customFields.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	values := make([]*models.CustomFieldValue, 0, len(m))
+	for name, value := range m {
+		if value == nil {
+			continue
+		}
+
+		f, ok := r.fields[name]
+		if !ok {
+			values = append(values, &models.CustomFieldValue{Name: name, Label: name, Value: value})
+
+			continue
+		}
+
+		label := f.label
+		if label == "" {
+			label = name
+		}
+
+		values = append(values, &models.CustomFieldValue{Name: name, Label: label, Type: f.typ, Value: f.convert(value)})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		fi, iok := r.fields[values[i].Name]
+		fj, jok := r.fields[values[j].Name]
+		switch {
+		case iok && jok && fi.position != fj.position:
+			return fi.position < fj.position
+		case iok && jok && fi.level != fj.level:
+			return fi.level < fj.level
+		case iok != jok:
+			return iok
+		default:
+			return values[i].Name < values[j].Name
+		}
+	})
+
+	return values
+}
+
+// convert returns the value converted to the Go type of the field: bool for checkboxes, int64 for numbers,
+// float64 for decimals, YYYY-MM-DD string for dates, and the choice value for dropdowns storing the choice ID.
+// The value is returned as is if it can't be converted.
+func (f *field) convert(v any) any {
+	switch f.typ {
+	case TypeCheckbox:
+		switch b := v.(type) {
+		case bool:
+			return b
+		case float64:
+			return b != 0
+		case string:
+			if parsed, err := strconv.ParseBool(b); err == nil {
+				return parsed
+			}
+		}
+	case TypeNumber:
+		switch n := v.(type) {
+		case float64:
+			if n == math.Trunc(n) {
+				return int64(n)
+			}
+		case string:
+			if parsed, err := strconv.ParseInt(strings.TrimSpace(n), 10, 64); err == nil {
+				return parsed
+			}
+		}
+	case TypeDecimal:
+		switch n := v.(type) {
+		case float64:
+			return n
+		case string:
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(n), 64); err == nil {
+				return parsed
+			}
+		}
+	case TypeDate:
+		if s, ok := v.(string); ok {
+			for _, layout := range []string{time.DateOnly, time.RFC3339} {
+				if t, err := time.Parse(layout, s); err == nil {
+					return t.Format(time.DateOnly)
+				}
+			}
+		}
+	case TypeDropdown, TypeNested:
+		if value, ok := f.choices[choiceKey(v)]; ok {
+			return value
+		}
+	}
+
+	return v
+}
+
+// choiceIDs returns choice values by IDs for choices exported as objects with id and value keys.
+// Other shapes of choices (lists of values, nested maps) need no decoding and give nil.
+func choiceIDs(choices any) map[string]string {
+	list, ok := choices.([]any)
+	if !ok {
+		return nil
+	}
+
+	ids := make(map[string]string)
+	for _, c := range list {
+		obj, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		value, ok := obj["value"].(string)
+		if !ok {
+			continue
+		}
+
+		switch id := obj["id"].(type) {
+		case float64:
+			ids[strconv.FormatInt(int64(id), 10)] = value
+		case string:
+			ids[id] = value
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return ids
+}
+
+// choiceKey returns the choice ID of the value the same way as choiceIDs formats IDs. Numbers are decoded
+// into float64, which fmt formats in the exponent notation for IDs as large as Freshdesk ones.
+func choiceKey(v any) string {
+	if n, ok := v.(float64); ok && n == math.Trunc(n) {
+		return strconv.FormatInt(int64(n), 10)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// Format returns the value as a human-readable text.
+func Format(v *models.CustomFieldValue) string {
+	switch value := v.Value.(type) {
+	case bool:
+		if value {
+			return "Yes"
+		}
+
+		return "No"
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []any:
+		s := make([]string, 0, len(value))
+		for _, item := range value {
+			s = append(s, fmt.Sprint(item))
+		}
+
+		return strings.Join(s, ", ")
+	default:
+		return fmt.Sprint(value)
+	}
+}
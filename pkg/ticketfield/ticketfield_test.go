@@ -0,0 +1,42 @@
+package ticketfield
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kirychukyurii/fd-import/models"
+)
+
+func TestResolveDropdownChoiceIDs(t *testing.T) {
+	var choices any
+	if err := json.Unmarshal([]byte(`[{"id": 43000123456, "value": "Hardware"}, {"id": "7", "value": "Software"}]`), &choices); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New([]*models.TicketField{{Name: "cf_category", Label: "Category", Type: TypeDropdown, Choices: choices}})
+	for _, tt := range []struct {
+		name  string
+		value string
+		want  any
+	}{
+		{name: "large numeric ID", value: `43000123456`, want: "Hardware"},
+		{name: "string ID", value: `"43000123456"`, want: "Hardware"},
+		{name: "numeric ID of string key", value: `7`, want: "Software"},
+		{name: "value", value: `"Hardware"`, want: "Hardware"},
+		{name: "unknown ID", value: `43000123457`, want: float64(43000123457)},
+	} {
+		var customFields any
+		if err := json.Unmarshal([]byte(`{"cf_category": `+tt.value+`}`), &customFields); err != nil {
+			t.Fatal(err)
+		}
+
+		values := r.Resolve(customFields)
+		if len(values) != 1 {
+			t.Fatalf("%s: got %d values, want 1", tt.name, len(values))
+		}
+
+		if values[0].Value != tt.want {
+			t.Errorf("%s: got %#v, want %#v", tt.name, values[0].Value, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package transcript renders imported tickets into human-readable HTML documents.
+package transcript
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+
+	"github.com/kirychukyurii/fd-import/models"
+	"github.com/kirychukyurii/fd-import/pkg/filestorage"
+	"github.com/kirychukyurii/fd-import/pkg/ticketfield"
+)
+
+//go:embed transcript.html
+var source string
+
+// policy sanitizes the stored HTML bodies: scripts, styles, event handlers and other active content are removed.
+var policy = bluemonday.UGCPolicy().AddTargetBlankToFullyQualifiedLinks(true)
+
+var tmpl = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"formatTime":    formatTime,
+	"formatBytes":   filestorage.HumanSize,
+	"fieldValue":    ticketfield.Format,
+	"messageClass":  messageClass,
+	"messageLabel":  messageLabel,
+	"body":          body,
+	"join":          strings.Join,
+	"attachmentURL": func(*models.Attachment) string { return "" },
+}).Parse(source))
+
+// Options configures the rendered document.
+//
+// AttachmentURL returns the link of the attachment. Attachments are listed without links if it is nil
+// or returns an empty string.
+type Options struct {
+	AttachmentURL func(a *models.Attachment) string
+}
+
+// Render writes the self-contained HTML document of the ticket into w: the header with ticket properties
+// and custom fields, and the chronological thread of the description and conversations.
+func Render(w io.Writer, ticket *models.Ticket, opts *Options) error {
+	t, err := tmpl.Clone()
+	if err != nil {
+		return fmt.Errorf("clone template: %v", err)
+	}
+
+	if opts != nil && opts.AttachmentURL != nil {
+		t = t.Funcs(template.FuncMap{"attachmentURL": opts.AttachmentURL})
+	}
+
+	// tickets loaded without field definitions show custom fields by their names
+	fields := ticket.CustomFieldValues
+	if fields == nil {
+		fields = ticketfield.New(nil).Resolve(ticket.CustomFields)
+	}
+
+	data := struct {
+		Ticket       *models.Ticket
+		CustomFields []*models.CustomFieldValue
+	}{
+		Ticket:       ticket,
+		CustomFields: fields,
+	}
+
+	if err := t.Execute(w, data); err != nil {
+		return fmt.Errorf("execute template: %v", err)
+	}
+
+	return nil
+}
+
+// body returns the sanitized HTML body or the escaped plain text body if the HTML one is empty.
+func body(html, text string) template.HTML {
+	if strings.TrimSpace(html) == "" {
+		return template.HTML(strings.ReplaceAll(template.HTMLEscapeString(text), "\n", "<br>"))
+	}
+
+	return template.HTML(policy.Sanitize(html))
+}
+
+// messageClass returns the CSS class of the conversation: private, incoming or outgoing.
+func messageClass(c *models.Conversation) string {
+	switch {
+	case c.Private:
+		return "private"
+	case c.Incoming:
+		return "incoming"
+	default:
+		return "outgoing"
+	}
+}
+
+// messageLabel returns the human-readable kind of the conversation.
+func messageLabel(c *models.Conversation) string {
+	switch {
+	case c.Private:
+		return "Private note"
+	case c.Incoming:
+		return "Incoming"
+	default:
+		return "Outgoing"
+	}
+}
+
+func formatTime(t any) string {
+	switch v := t.(type) {
+	case time.Time:
+		return v.Format(time.DateTime)
+	case *time.Time:
+		if v != nil {
+			return v.Format(time.DateTime)
+		}
+	}
+
+	return ""
+}